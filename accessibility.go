@@ -0,0 +1,126 @@
+package bubbleup
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Words prefixing the included alert types in accessibility mode, read
+// naturally by screen readers.
+const (
+	InfoSpokenPrefix  = "Info:"
+	WarnSpokenPrefix  = "Warning:"
+	ErrorSpokenPrefix = "Error:"
+	DebugSpokenPrefix = "Debug:"
+)
+
+// SpokenIcons prefixes alerts with severity words, such as "Error:", rather
+// than glyphs. Alert types it has no word for are prefixed with their key.
+var SpokenIcons IconSet = spokenIcons{}
+
+type spokenIcons struct{}
+
+func (spokenIcons) Icon(key string) (string, bool) {
+	switch key {
+	case InfoKey:
+		return InfoSpokenPrefix, true
+	case WarnKey:
+		return WarnSpokenPrefix, true
+	case ErrorKey:
+		return ErrorSpokenPrefix, true
+	case DebugKey:
+		return DebugSpokenPrefix, true
+	default:
+		return key + ":", true
+	}
+}
+
+// AccessibilityOptions configures WithAccessibility.
+type AccessibilityOptions struct {
+	// (Opt) Whether to ring the terminal bell when Warn or Error alerts appear
+	Bell bool
+
+	// (Opt) Where the bell is rung. Defaults to os.Stderr
+	BellOutput io.Writer
+
+	// (Opt) Stream receiving every alert as plain text, one alert per line
+	// with details indented below, such as os.Stderr or a log file a screen
+	// reader can follow
+	Mirror io.Writer
+}
+
+// accessibility holds the settings of WithAccessibility, and serializes
+// writes from concurrent commands.
+type accessibility struct {
+	opts AccessibilityOptions
+	mu   sync.Mutex
+
+	// look replaces the model's appearance for every alert, so reduced
+	// motion and high contrast outlast later themes and config reloads
+	look appearance
+}
+
+// WithAccessibility returns a new AlertModel suited to users with motion
+// sensitivity or screen readers. Alerts appear without fading, use the
+// colors and heavy border of HighContrastTheme, and are prefixed with
+// SpokenIcons words instead of glyphs, even for alert types with a Prefix of
+// their own. Themes applied afterwards don't change any of this. The bell
+// and a plain text mirror of every alert can be enabled through opts.
+func (m AlertModel) WithAccessibility(opts AccessibilityOptions) AlertModel {
+	theme := HighContrastTheme()
+	theme.ShowTitle = false // The spoken prefix already names the alert type
+	theme.DisableFade = true
+
+	look := theme.appearance()
+	look.colors = validColors(theme.Colors)
+
+	m.accessibility = &accessibility{opts: opts, look: look}
+	return m.WithIconSet(SpokenIcons)
+}
+
+// appearance returns the presentation settings alerts are raised with,
+// which accessibility mode takes over.
+func (m AlertModel) appearance() appearance {
+	if m.accessibility != nil {
+		return m.accessibility.look
+	}
+	return m.look
+}
+
+// announceCmd returns the tea.Cmd ringing the bell and mirroring n, if
+// accessibility mode asks for either.
+func (m AlertModel) announceCmd(n *alert) tea.Cmd {
+	acc := m.accessibility
+	if n == nil || acc == nil {
+		return nil
+	}
+
+	bell := acc.opts.Bell && (n.key == WarnKey || n.key == ErrorKey)
+	mirror := acc.opts.Mirror
+	if !bell && mirror == nil {
+		return nil
+	}
+
+	lines := strings.Split(n.plainText(), "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = "  " + lines[i]
+	}
+	text := n.prefix + " " + strings.Join(lines, "\n") + "\n"
+
+	return func() tea.Msg {
+		acc.mu.Lock()
+		defer acc.mu.Unlock()
+
+		if bell {
+			_, _ = io.WriteString(terminalOutput(acc.opts.BellOutput), "\a")
+		}
+		if mirror != nil {
+			// A failing mirror must not take the alerts down with it.
+			_, _ = io.WriteString(mirror, text)
+		}
+		return nil
+	}
+}
@@ -2,12 +2,17 @@ package bubbleup
 
 import (
 	"fmt"
-	"log"
+	"math"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
 )
 
 // Alert keys for the included alert types.
@@ -52,9 +57,13 @@ const (
 	DebugUniPrefix = DebugASCIIPrefix
 )
 
+// Marker in front of the counter shown on repeated alerts.
+const repeatBadgeMarker = "×"
+
 // Defaults used by the notification rendering.
 const (
 	DefaultLerpIncrement = 0.18
+	DefaultLerpStart     = 0.3
 )
 
 // Colors used by the included alert types.
@@ -74,8 +83,6 @@ var (
 	errorColor, _ = colorful.Hex(ErrorColor)
 	debugColor, _ = colorful.Hex(DebugColor)
 	backColor, _  = colorful.Hex(BackColor)
-
-	baseStyle = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder())
 )
 
 var parsedColors = map[string]colorful.Color{
@@ -91,40 +98,93 @@ type alertMsg struct {
 	alertKey string
 	msg      string
 	dur      time.Duration
+	link     string
+
+	// details lists the error chain of alerts raised with ErrorAlertCmd
+	details []errorDetail
+
+	// next is run once the alert is raised, re-arming subscriptions
+	next tea.Cmd
+
+	// position overrides the model's position for this alert, and id
+	// identifies it to later requests, see AlertRequest
+	position Position
+	id       string
+
+	// summary marks the alert summing up suppressed alerts, which the rate
+	// limit always lets through
+	summary bool
 
 	// TODO:
 	// animation: how the notification should appear and disappear
 	// style: Mimic nvim.notify's style options perhaps?
 }
 
-func (m AlertModel) newAlert(key, msg string, dur time.Duration) *alert {
-	if msg == "" || key == "" {
+func (m AlertModel) newAlert(req alertMsg) *alert {
+	if req.msg == "" || req.alertKey == "" {
 		return nil
 	}
 
-	alertDef, ok := m.alertTypes[key]
+	alertDef, ok := m.registry.Lookup(req.alertKey)
 
 	if !ok {
 		return nil
 	}
+	look := m.appearance()
+	alertDef = look.themed(alertDef)
+
+	// Only fading alerts need the color they fade in from, which spares
+	// querying the terminal background for alerts shown at full color.
+	color := alertDef.terminalColor(m.hasDarkBackground)
+	foreColor := alertDef.foreColor(m.hasDarkBackground)
+	fromColor := foreColor
+	if look.lerpStart < 1 {
+		fromColor = alertDef.fromColor(m.background, m.hasDarkBackground)
+	}
 
-	foreColor, ok := parsedColors[alertDef.ForeColor]
-	if !ok {
-		// Can safely discard error because we validated the color
-		// when registering the alert defition
-		foreColor, _ = colorful.Hex(alertDef.ForeColor)
+	prefix := prefixFor(alertDef, m.iconSet)
+	if m.accessibility != nil {
+		prefix, _ = SpokenIcons.Icon(alertDef.Key)
+	}
+
+	var markup []block
+	if m.useMarkup {
+		markup = parseMarkup(req.msg)
+	}
+
+	var link string
+	if req.link != "" {
+		link = hyperlinkURL(req.link)
+	}
+
+	position := m.position
+	if req.position.IsValid() {
+		position = req.position
 	}
 
 	return &alert{
-		message:     msg,
-		deathTime:   time.Now().Add(dur),
-		prefix:      alertDef.Prefix,
+		key:         req.alertKey,
+		message:     req.msg,
+		markup:      markup,
+		link:        link,
+		details:     req.details,
+		detailsKey:  m.detailsKey,
+		deathTime:   time.Now().Add(req.dur),
+		prefix:      prefix,
+		color:       color,
 		foreColor:   foreColor,
+		backColor:   fromColor,
 		style:       alertDef.Style,
 		width:       m.width,
 		minWidth:    m.minWidth,
-		curLerpStep: 0.3,
-		position:    m.position,
+		curLerpStep: look.lerpStart,
+		look:        look,
+		position:    position,
+		id:          req.id,
+		count:       1,
+		renderer:    m.renderer,
+		profile:     m.colorProfile(),
+		dumbTerm:    os.Getenv("TERM") == "dumb",
 	}
 
 }
@@ -132,37 +192,79 @@ func (m AlertModel) newAlert(key, msg string, dur time.Duration) *alert {
 // alert represents an instance of an actual alert, including
 // all information needed to render and destroy itself
 type alert struct {
+	key       string
 	message   string
+	markup    []block
+	link      string
 	deathTime time.Time
+
+	details    []errorDetail
+	detailsKey string
+	expanded   bool
+
 	prefix    string
+	color     lipgloss.TerminalColor
 	foreColor colorful.Color
+	backColor colorful.Color
 	style     lipgloss.Style
 	width     int
 	minWidth  int
 
 	curLerpStep float64
 	position    Position
+	id          string
+	count       int
+	flashUntil  time.Time
+	renderer    *lipgloss.Renderer
+	profile     termenv.Profile
+	dumbTerm    bool
+	look        appearance
+}
+
+// newStyle returns an empty style for the alert's renderer.
+func (n *alert) newStyle() lipgloss.Style {
+	if n.renderer == nil {
+		return lipgloss.NewStyle()
+	}
+	return n.renderer.NewStyle()
+}
+
+// plainText returns the alert's message without any markup, followed by
+// its details if it has any.
+func (n *alert) plainText() string {
+	text := n.message
+	if n.markup != nil {
+		text = plainMarkup(n.markup, "\n")
+	}
+	if len(n.details) > 0 {
+		text += "\n" + n.detailsText()
+	}
+	return text
 }
 
 // render will render the given alert based on its values
 // Returns the string representation of the alert, ready to be
 // overlayed onto the main content.
 func (n *alert) render() string {
-	newColor := backColor.BlendLab(n.foreColor, n.curLerpStep)
-	lipColor := lipgloss.Color(newColor.Hex())
+	var lipColor lipgloss.TerminalColor = lipgloss.NoColor{}
+	if n.profile != termenv.Ascii {
+		// Only blend through RGB while fading, so the alert ends up in the
+		// color it was defined with.
+		lipColor = n.color
+		if progress := fadeProgress(n.curLerpStep, n.profile); progress < 1 {
+			lipColor = lipgloss.Color(n.backColor.BlendLab(n.foreColor, progress).Hex())
+		}
+	}
 
 	// Calculate actual width based on minWidth setting
 	actualWidth := n.width // default to max/fixed width
 
 	if n.minWidth > 0 {
 		// Dynamic mode: measure message width
-		messageText := fmt.Sprintf("%v %v", n.prefix, n.message)
-
-		// Get the width of the message text itself
-		messageWidth := lipgloss.Width(messageText)
+		messageWidth := n.messageWidth()
 
 		// Account for extra space needed, determined imperically
-		messageWidth += 3
+		messageWidth += 2*n.look.paddingX + 1
 
 		// Clamp between min and max
 		if messageWidth < n.minWidth {
@@ -174,22 +276,140 @@ func (n *alert) render() string {
 		}
 	}
 
-	newStyle := baseStyle.
+	newStyle := n.newStyle().
+		BorderStyle(n.look.border).
 		Foreground(lipColor).
 		BorderForeground(lipColor).
 		Width(actualWidth).
-		Padding(0, 1)
+		Padding(0, n.look.paddingX)
+
+	if time.Now().Before(n.flashUntil) {
+		// Confirm a copy by flashing a heavier frame, which keeps the size.
+		newStyle = newStyle.BorderStyle(lipgloss.ThickBorder()).Bold(true)
+	}
 
 	// Compute width available for text inside border+padding.
-	textWidth := actualWidth - 2
+	textWidth := actualWidth - 2*n.look.paddingX
 	if textWidth < 1 {
 		textWidth = 1
 	}
 
-	content := hangingWrap(n.prefix, n.message, textWidth)
+	prefix := n.bodyPrefix()
+
+	badgeStyle := n.newStyle().Foreground(lipColor).Bold(true)
+
+	var content string
+	if n.markup != nil {
+		content = renderMarkup(prefix, n.badgedMarkup(), textWidth, n.newStyle().Foreground(lipColor))
+	} else {
+		content = hangingWrap(prefix, n.badgedMessage(badgeStyle), textWidth)
+	}
+	if n.link != "" {
+		content = hyperlinkLines(content, n.link)
+	}
+	if n.look.showTitle {
+		titleStyle := n.look.titleStyle.Inherit(n.newStyle().Foreground(lipColor))
+		content = n.icon() + " " + titleStyle.Render(n.key) + "\n" + content
+	}
+	if len(n.details) > 0 {
+		detailStyle := n.newStyle().Foreground(lipColor).Faint(true)
+		content += "\n" + n.renderDetails(lipgloss.Width(prefix+" "), textWidth, detailStyle)
+	}
 	return newStyle.Render(content)
 }
 
+// repeatedBy reports whether other repeats n: both have the same ID, or
+// neither has one and they share their alert type and message.
+func (n *alert) repeatedBy(other *alert) bool {
+	if n.id != "" || other.id != "" {
+		return n.id == other.id
+	}
+	return n.key == other.key && n.message == other.message
+}
+
+// badge returns the counter shown on repeated alerts, such as "×12", or ""
+// if the alert wasn't repeated.
+func (n *alert) badge() string {
+	if n.count <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("%s%d", repeatBadgeMarker, n.count)
+}
+
+// badgedMessage returns the message followed by the badge in style.
+func (n *alert) badgedMessage(style lipgloss.Style) string {
+	if badge := n.badge(); badge != "" {
+		return n.message + " " + style.Render(badge)
+	}
+	return n.message
+}
+
+// badgedMarkup returns the markup blocks with the badge in bold after the
+// last one.
+func (n *alert) badgedMarkup() []block {
+	badge := n.badge()
+	if badge == "" || len(n.markup) == 0 {
+		return n.markup
+	}
+
+	blocks := slices.Clone(n.markup)
+	last := &blocks[len(blocks)-1]
+	last.spans = append(slices.Clone(last.spans), span{text: " "}, span{text: badge, flags: spanBold})
+	return blocks
+}
+
+// bodyPrefix returns the prefix hanging in front of the message. With a
+// title the icon moves to the title line, leaving blank space to keep the
+// message aligned with the title text.
+func (n *alert) bodyPrefix() string {
+	if n.look.showTitle {
+		return strings.Repeat(" ", lipgloss.Width(n.icon()))
+	}
+	return n.icon()
+}
+
+// icon returns the prefix as drawn. Without colors, alert types can only be
+// told apart by their prefix, so it stands out in bold reverse video, and
+// falls back to the alert type's key if the type has no prefix.
+func (n *alert) icon() string {
+	if n.profile != termenv.Ascii {
+		return n.prefix
+	}
+
+	icon := n.prefix
+	if strings.TrimSpace(icon) == "" {
+		icon = n.key + ":"
+	}
+	if n.dumbTerm {
+		// Dumb terminals can't be relied on for any attributes.
+		return icon
+	}
+	return ansi.Style{}.Bold().Reverse(true).Styled(icon)
+}
+
+// messageWidth returns the width of the prefixed message as it would be
+// rendered without any wrapping.
+func (n *alert) messageWidth() int {
+	prefix := n.bodyPrefix()
+
+	var width int
+	if n.markup != nil {
+		width = markupWidth(prefix, n.badgedMarkup())
+	} else {
+		// Get the width of the message text itself
+		width = lipgloss.Width(fmt.Sprintf("%v %v", prefix, n.badgedMessage(lipgloss.NewStyle())))
+	}
+
+	if n.look.showTitle {
+		width = max(width, lipgloss.Width(n.icon()+" "+n.key))
+	}
+	if len(n.details) > 0 {
+		_, detailsWidth := getLines(n.renderDetails(lipgloss.Width(prefix+" "), math.MaxInt32, lipgloss.NewStyle()))
+		width = max(width, detailsWidth)
+	}
+	return width
+}
+
 // Region: Model stuff
 
 // AlertDefinition is all the information needed to register a new alert type.
@@ -197,15 +417,30 @@ type AlertDefinition struct {
 	// (Req) Unique key used to refer to an alert type
 	Key string
 
-	// (Req) Hex code of the color you want your alert to be
+	// (Req, unless Color is set) Hex code of the color you want your alert to be
 	ForeColor string
 
+	// (Opt) Color of the alert, taking precedence over ForeColor. Accepts any
+	// lipgloss.TerminalColor: hex codes and ANSI indices via lipgloss.Color
+	// or lipgloss.ANSIColor, as well as lipgloss.AdaptiveColor,
+	// lipgloss.CompleteColor and lipgloss.CompleteAdaptiveColor
+	Color lipgloss.TerminalColor
+
+	// (Opt) Color the alert fades in from. Defaults to the AlertModel's
+	// background color, see AlertModel.WithBackgroundColor
+	BackColor lipgloss.TerminalColor
+
 	// (Opt) lipgloss.Style used to render the alert
 	Style lipgloss.Style
 
-	// (Opt) String used to prefix the alert message
+	// (Opt) String used to prefix the alert message. Defaults to the
+	// glyph for Key in the AlertModel's IconSet
 	Prefix string
 
+	// (Opt) Whether alerts of this type are mirrored as desktop
+	// notifications, see AlertModel.WithDesktopNotifications
+	Desktop bool
+
 	// DefaultDur time.Duration
 	// DefaultPos
 	// Default
@@ -220,80 +455,69 @@ func (m AlertModel) NewAlertCmd(alertType, message string) tea.Cmd {
 	}
 }
 
-// RegisterNewAlertType will registery a new alert type based on the provided
-// AlertDefintion. This can also be used to overwrite the provided defaults
-// by providing an AlertDefintion with one of the default keys.
-func (m AlertModel) RegisterNewAlertType(definition AlertDefinition) {
-	_, err := colorful.Hex(definition.ForeColor)
-	if err != nil {
-		log.Fatal(err)
-		return
+// NewIDAlertCmd works like NewAlertCmd, but identifies the alert with id.
+// While an alert with the same id is on screen, the new one is merged into
+// it as a repeat, replacing its message and counting the repeats, even if
+// the message changed, such as the attempt number of a retry loop.
+func (m AlertModel) NewIDAlertCmd(alertType, message, id string) tea.Cmd {
+	return func() tea.Msg {
+		return alertMsg{alertKey: alertType, msg: message, dur: m.duration, id: id}
 	}
+}
 
-	if m.alertTypes == nil {
-		m.alertTypes = make(map[string]AlertDefinition)
+// NewLinkAlertCmd works like NewAlertCmd, but also attaches a link to the
+// alert. Terminals that support OSC 8 hyperlinks make the alert clickable.
+// The target can be a URL, or a file path which is resolved to an absolute
+// file:// URL.
+func (m AlertModel) NewLinkAlertCmd(alertType, message, target string) tea.Cmd {
+	return func() tea.Msg {
+		return alertMsg{alertKey: alertType, msg: message, dur: m.duration, link: target}
 	}
-
-	m.alertTypes[definition.Key] = definition
 }
 
-var unicodePrefixes = map[string]string{
-	InfoKey:  InfoUnicodePrefix,
-	WarnKey:  WarningUnicodePrefix,
-	ErrorKey: ErrorUnicodePrefix,
-	DebugKey: DebugUnicodePrefix,
+// RegisterNewAlertType will registery a new alert type based on the provided
+// AlertDefintion. Returns an error if the definition is invalid, or if an
+// alert type is already registered under its key; use ReplaceAlertType to
+// overwrite the provided defaults or a previous registration.
+// The alert type is added to the model's Registry, so models sharing it see
+// the new type too.
+func (m AlertModel) RegisterNewAlertType(definition AlertDefinition) error {
+	return m.registry.Register(definition)
 }
 
-// Registers all the alert types that ship with BubbleUp by out of the box.
-func (m AlertModel) registerDefaultAlertTypes() {
-	var (
-		infoPref  string
-		warnPref  string
-		errPref   string
-		debugPref string
-	)
-
-	if m.useNerdFont {
-		infoPref = InfoNerdSymbol
-		warnPref = WarnNerdSymbol
-		errPref = ErrorNerdSymbol
-		debugPref = DebugNerdSymbol
-	} else {
-		infoPref = InfoASCIIPrefix
-		warnPref = WarningASCIIPrefix
-		errPref = ErrorASCIIPrefix
-		debugPref = DebugASCIIPrefix
-	}
-
-	infoDef := AlertDefinition{
-		Key:       InfoKey,
-		Prefix:    infoPref,
-		ForeColor: InfoColor,
-	}
-
-	m.RegisterNewAlertType(infoDef)
+// ReplaceAlertType registers the provided AlertDefinition, overwriting any
+// alert type already registered under its key, such as one of the defaults.
+// Returns an error if the definition is invalid.
+func (m AlertModel) ReplaceAlertType(definition AlertDefinition) error {
+	return m.registry.Replace(definition)
+}
 
-	warnDef := AlertDefinition{
-		Key:       WarnKey,
-		Prefix:    warnPref,
-		ForeColor: WarnColor,
-	}
+// UnregisterAlertType removes the alert type registered under key.
+// Returns false if there was no such alert type.
+func (m AlertModel) UnregisterAlertType(key string) bool {
+	return m.registry.Unregister(key)
+}
 
-	m.RegisterNewAlertType(warnDef)
+// AlertType returns the definition of the alert type registered under key.
+func (m AlertModel) AlertType(key string) (AlertDefinition, bool) {
+	return m.registry.Lookup(key)
+}
 
-	errorDef := AlertDefinition{
-		Key:       ErrorKey,
-		Prefix:    errPref,
-		ForeColor: ErrorColor,
-	}
+// AlertTypes returns the definitions of all registered alert types, sorted by key.
+func (m AlertModel) AlertTypes() []AlertDefinition {
+	return m.registry.Definitions()
+}
 
-	m.RegisterNewAlertType(errorDef)
+// NewDefaultRegistry returns a Registry holding the alert types that ship
+// with BubbleUp. Their prefixes come from the AlertModel's IconSet.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
 
-	debugDef := AlertDefinition{
-		Key:       DebugKey,
-		Prefix:    debugPref,
-		ForeColor: DebugColor,
-	}
+	// Errors are ignored because the included alert types are known to be valid.
+	_ = r.Replace(AlertDefinition{Key: InfoKey, ForeColor: InfoColor})
+	_ = r.Replace(AlertDefinition{Key: WarnKey, ForeColor: WarnColor})
+	_ = r.Replace(AlertDefinition{Key: ErrorKey, ForeColor: ErrorColor})
+	_ = r.Replace(AlertDefinition{Key: DebugKey, ForeColor: DebugColor})
 
-	m.RegisterNewAlertType(debugDef)
+	return r
 }
@@ -0,0 +1,160 @@
+package bubbleup
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// IconsEnvVar names the environment variable that overrides icon detection.
+// Set it to "nerdfont", "unicode", "ascii" or "emoji".
+const IconsEnvVar = "BUBBLEUP_ICONS"
+
+// Names of the included icon sets, as used by IconsEnvVar and Capabilities.
+const (
+	NerdFontIconsName = "nerdfont"
+	UnicodeIconsName  = "unicode"
+	ASCIIIconsName    = "ascii"
+	EmojiIconsName    = "emoji"
+)
+
+// Terminals known to bundle NerdFont glyphs, keyed by TERM_PROGRAM or TERM.
+var nerdFontTerminals = map[string]bool{
+	"WezTerm":       true,
+	"ghostty":       true,
+	"xterm-ghostty": true,
+	"xterm-kitty":   true,
+}
+
+// Capabilities describes what DetectCapabilities decided about the terminal.
+type Capabilities struct {
+	// Icon set chosen for the terminal, and its name
+	Icons     IconSet
+	IconsName string
+
+	// Color profile alerts are rendered with
+	ColorProfile termenv.Profile
+
+	// Whether the locale uses UTF-8, and whether the terminal is known to
+	// render NerdFont glyphs
+	UTF8     bool
+	NerdFont bool
+
+	// Human readable explanation of the icon choice
+	Reason string
+}
+
+// String summarizes the capabilities, suitable for display or logging.
+func (c Capabilities) String() string {
+	return fmt.Sprintf("icons=%s colors=%s (%s)", c.IconsName, profileName(c.ColorProfile), c.Reason)
+}
+
+// DetectCapabilities inspects the environment to pick the richest icon set
+// and color profile the terminal can be expected to display:
+//   - IconsEnvVar, if set to the name of an included set, always wins
+//   - TERM=dumb, or a locale (LC_ALL, LC_CTYPE, LANG) without UTF-8, gets ASCII
+//   - Terminals known to bundle NerdFont glyphs get NerdFont, others Unicode
+//   - Colors follow lipgloss's detected profile, honoring NO_COLOR and
+//     CLICOLOR_FORCE, and are disabled for TERM=dumb
+func DetectCapabilities() Capabilities {
+	return detectCapabilities(os.Getenv, lipgloss.ColorProfile())
+}
+
+func detectCapabilities(getenv func(string) string, profile termenv.Profile) Capabilities {
+	term := getenv("TERM")
+	termProgram := getenv("TERM_PROGRAM")
+
+	c := Capabilities{
+		ColorProfile: profile,
+		UTF8:         isUTF8Locale(getenv),
+		NerdFont:     nerdFontTerminals[termProgram] || nerdFontTerminals[term],
+	}
+
+	if getenv("NO_COLOR") != "" || term == "dumb" {
+		c.ColorProfile = termenv.Ascii
+	}
+
+	if name := strings.ToLower(getenv(IconsEnvVar)); iconSetByName(name) != nil {
+		c.IconsName = name
+		c.Reason = "set by " + IconsEnvVar
+	} else {
+		switch {
+		case term == "dumb":
+			c.IconsName = ASCIIIconsName
+			c.Reason = "dumb terminal"
+		case !c.UTF8:
+			c.IconsName = ASCIIIconsName
+			c.Reason = "locale is not UTF-8"
+		case c.NerdFont:
+			c.IconsName = NerdFontIconsName
+			c.Reason = "terminal bundles NerdFont glyphs"
+		default:
+			c.IconsName = UnicodeIconsName
+			c.Reason = "UTF-8 locale, NerdFont not known to be available"
+		}
+	}
+	c.Icons = iconSetByName(c.IconsName)
+
+	return c
+}
+
+// isUTF8Locale reports whether the effective locale uses UTF-8. Windows
+// Terminal doesn't set a locale, but always supports UTF-8.
+func isUTF8Locale(getenv func(string) string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return getenv("WT_SESSION") != ""
+}
+
+// iconSetByName returns the included icon set with the given name, or nil.
+func iconSetByName(name string) IconSet {
+	switch name {
+	case NerdFontIconsName:
+		return NerdFontIcons
+	case UnicodeIconsName:
+		return UnicodeIcons
+	case ASCIIIconsName:
+		return ASCIIIcons
+	case EmojiIconsName:
+		return EmojiIcons
+	default:
+		return nil
+	}
+}
+
+func profileName(p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return "truecolor"
+	case termenv.ANSI256:
+		return "ansi256"
+	case termenv.ANSI:
+		return "ansi"
+	default:
+		return "none"
+	}
+}
+
+var renderers sync.Map // termenv.Profile -> *lipgloss.Renderer
+
+// rendererFor returns a shared renderer producing output for the given profile.
+func rendererFor(p termenv.Profile) *lipgloss.Renderer {
+	if r, ok := renderers.Load(p); ok {
+		return r.(*lipgloss.Renderer)
+	}
+	r := lipgloss.NewRenderer(os.Stdout)
+	r.SetColorProfile(p)
+	// Share the default renderer's answer, so a fresh renderer doesn't query
+	// the terminal background again, possibly from within Update.
+	r.SetHasDarkBackground(lipgloss.HasDarkBackground())
+	actual, _ := renderers.LoadOrStore(p, r)
+	return actual.(*lipgloss.Renderer)
+}
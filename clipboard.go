@@ -0,0 +1,63 @@
+package bubbleup
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How long an alert flashes to confirm its message was copied.
+const copyFlashDuration = 600 * time.Millisecond
+
+// copiedMsg reports the outcome of copying an alert to the clipboard.
+type copiedMsg struct {
+	alert *alert
+	err   error
+}
+
+// CopyAlertCmd returns a tea.Cmd that copies the active alert's message to
+// the system clipboard using an OSC 52 escape sequence, and briefly flashes
+// the alert once done. OSC 52 is handled by the terminal itself, so it also
+// works over SSH. Inside tmux or screen the sequence is wrapped so it passes
+// through to the outer terminal; tmux needs allow-passthrough enabled.
+// Returns nil if there is no active alert.
+func (m AlertModel) CopyAlertCmd() tea.Cmd {
+	if m.activeAlert == nil {
+		return nil
+	}
+
+	target := m.activeAlert
+	out := terminalOutput(m.clipboardOut)
+
+	return func() tea.Msg {
+		return copiedMsg{alert: target, err: copyToClipboard(out, target.plainText())}
+	}
+}
+
+// copyToClipboard writes an OSC 52 sequence setting the clipboard to text.
+func copyToClipboard(w io.Writer, text string) error {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+
+	_, err := seq.WriteTo(w)
+	return err
+}
+
+// terminalOutput returns w, or os.Stderr if w is nil. Escape sequences meant
+// for the terminal itself default to stderr, because it reaches the same
+// terminal as stdout without interleaving with Bubble Tea's rendering there.
+func terminalOutput(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stderr
+	}
+	return w
+}
@@ -0,0 +1,290 @@
+// Command bubbleup displays a BubbleUp alert from shell scripts.
+//
+//	bubbleup --type warn --position top-right --duration 3s "Deploy paused"
+//	make 2>&1 | tail -n 5 | bubbleup --type error --mode toast
+//	bubbleup --socket "$XDG_RUNTIME_DIR/myapp.sock" --type info "Backup done"
+//
+// Inline mode, the default, prints the alert to stderr and exits, falling
+// back to a line of plain text when stderr isn't a terminal. Toast mode
+// shows the alert on the alternate screen until it expires or a key is
+// pressed. With --socket, the alert is sent to a running program listening
+// with bubbleup.ListenAlerts instead. The message is read from stdin when no
+// arguments are given, or when the only argument is "-".
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"go.dalton.dog/bubbleup"
+	"golang.org/x/term"
+)
+
+// Modes accepted by --mode.
+const (
+	modeInline = "inline"
+	modeToast  = "toast"
+)
+
+// errUsage reports bad arguments, after the flag package printed usage.
+var errUsage = errors.New("usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stderr); err != nil {
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "bubbleup:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin *os.File, stderr io.Writer) error {
+	var (
+		cfg        bubbleup.Config
+		configPath string
+		socketPath string
+		alertType  string
+		mode       string
+		duration   time.Duration
+		position   = bubbleup.TopRightPosition
+	)
+
+	flags := flag.NewFlagSet("bubbleup", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: bubbleup [flags] [message | -]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Displays an alert. The message is read from stdin when not given.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&configPath, "config", "", "JSON, YAML or TOML `file` with alert settings and alert types, overridden by other flags")
+	flags.StringVar(&socketPath, "socket", "", "send the alert to the program listening on this Unix `socket` instead of showing it")
+	flags.StringVar(&alertType, "type", "info", "alert `type`: info, warn, error, debug, or one from --config")
+	flags.StringVar(&mode, "mode", modeInline, "\"inline\" prints to stderr, \"toast\" shows the alert on the alternate screen")
+	flags.TextVar(&position, "position", position, "toast `position`: top-left, top-center, top-right, bottom-left, bottom-center or bottom-right")
+	flags.DurationVar(&duration, "duration", 3*time.Second, "how long a toast is shown")
+
+	flags.IntVar(&cfg.Width, "width", 50, "maximum alert width in `cells`")
+	flags.IntVar(&cfg.MinWidth, "min-width", 10, "minimum alert width in `cells`, alerts shrink to fit short messages")
+	flags.StringVar(&cfg.Icons, "icons", bubbleup.AutoIconsName, "icon `set`: nerdfont, unicode, ascii, emoji or auto")
+	flags.StringVar(&cfg.Theme, "theme", "", "theme `name`: default, minimal, nvim-notify, catppuccin, solarized or high-contrast")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+
+	if mode != modeInline && mode != modeToast {
+		fmt.Fprintf(stderr, "invalid value %q for flag -mode: must be inline or toast\n", mode)
+		flags.Usage()
+		return errUsage
+	}
+
+	// The default minimum width shouldn't make a narrow --width invalid.
+	minWidthGiven := false
+	flags.Visit(func(f *flag.Flag) { minWidthGiven = minWidthGiven || f.Name == "min-width" })
+	if !minWidthGiven {
+		cfg.MinWidth = min(cfg.MinWidth, cfg.Width)
+	}
+
+	cfg.Duration = bubbleup.Duration(duration)
+	cfg.Position = position
+	if configPath != "" {
+		if err := loadConfig(configPath, &cfg, flags); err != nil {
+			return err
+		}
+	}
+
+	message, err := readMessage(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	if message == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	if socketPath != "" {
+		return sendToSocket(socketPath, alertType, message, flags)
+	}
+
+	alerts, err := bubbleup.NewAlertModelFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	key, ok := lookupAlertType(alerts.AlertTypes(), alertType)
+	if !ok {
+		return fmt.Errorf("unknown alert type %q", alertType)
+	}
+
+	if mode == modeToast {
+		return showToast(*alerts, key, message)
+	}
+	return bubbleup.NewAlertWriter(stderr, *alerts).Send(key, message)
+}
+
+// loadConfig reads the config file at path into cfg, keeping the values of
+// flags given on the command line, and the --duration default if the file
+// doesn't set a duration.
+func loadConfig(path string, cfg *bubbleup.Config, flags *flag.FlagSet) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	loaded, err := bubbleup.ConfigParser(path)(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if loaded.Duration == 0 {
+		loaded.Duration = cfg.Duration
+	}
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "width":
+			loaded.Width = cfg.Width
+		case "min-width":
+			loaded.MinWidth = cfg.MinWidth
+		case "icons":
+			loaded.Icons = cfg.Icons
+		case "theme":
+			loaded.Theme = cfg.Theme
+		case "duration":
+			loaded.Duration = cfg.Duration
+		case "position":
+			loaded.Position = cfg.Position
+		}
+	})
+	*cfg = loaded
+	return nil
+}
+
+// sendToSocket sends the alert to a running program, passing on the duration
+// and position only if given, so the program's own defaults apply otherwise.
+func sendToSocket(path, alertType, message string, flags *flag.FlagSet) error {
+	key, ok := lookupAlertType(bubbleup.NewDefaultRegistry().Definitions(), alertType)
+	if !ok {
+		// The program may have alert types of its own, so let it decide.
+		key = alertType
+	}
+
+	req := bubbleup.AlertRequest{Key: key, Message: message}
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "duration":
+			req.Duration = f.Value.(flag.Getter).Get().(time.Duration)
+		case "position":
+			req.Position = *f.Value.(flag.Getter).Get().(*bubbleup.Position)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return bubbleup.SendAlert(ctx, path, req)
+}
+
+// readMessage joins the arguments into the message, or reads it from stdin
+// when there are none or the only one is "-".
+func readMessage(args []string, stdin *os.File) (string, error) {
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		return strings.Join(args, " "), nil
+	}
+	if len(args) == 0 && term.IsTerminal(int(stdin.Fd())) {
+		// Don't wait on a person who doesn't know they're being asked.
+		return "", nil
+	}
+
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading message: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// lookupAlertType returns the key of the alert type named name, ignoring case.
+func lookupAlertType(definitions []bubbleup.AlertDefinition, name string) (string, bool) {
+	for _, definition := range definitions {
+		if strings.EqualFold(definition.Key, name) {
+			return definition.Key, true
+		}
+	}
+	// Accept "warning" for Warn, as spelled out by the spoken prefixes.
+	if strings.EqualFold(name, "warning") {
+		return bubbleup.WarnKey, true
+	}
+	return "", false
+}
+
+// toast shows a single alert on an otherwise blank screen, quitting once it
+// expires or a key is pressed.
+type toast struct {
+	alerts        bubbleup.AlertModel
+	raise         tea.Cmd
+	shown         bool
+	width, height int
+}
+
+func showToast(alerts bubbleup.AlertModel, key, message string) error {
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithOutput(os.Stderr)}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		// Stdin carried the message, so read keys from the terminal itself.
+		opts = append(opts, tea.WithInputTTY())
+	}
+
+	// The toast is drawn on stderr, so ask it rather than stdout about colors.
+	output := termenv.NewOutput(os.Stderr)
+	alerts = alerts.
+		WithColorProfile(output.EnvColorProfile()).
+		WithDarkBackground(output.HasDarkBackground()).
+		WithAllowEscToClose()
+
+	t := toast{alerts: alerts, raise: alerts.NewAlertCmd(key, message)}
+	_, err := tea.NewProgram(t, opts...).Run()
+	return err
+}
+
+func (t toast) Init() tea.Cmd {
+	return tea.Batch(t.alerts.Init(), t.raise)
+}
+
+func (t toast) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		t.width, t.height = msg.Width, msg.Height
+		return t, nil
+	case tea.KeyMsg:
+		return t, tea.Quit
+	}
+
+	outAlert, cmd := t.alerts.Update(msg)
+	t.alerts = outAlert.(bubbleup.AlertModel)
+
+	if t.alerts.HasActiveAlert() {
+		t.shown = true
+	} else if t.shown {
+		return t, tea.Quit
+	}
+	return t, cmd
+}
+
+func (t toast) View() string {
+	blank := strings.Repeat(" ", t.width)
+	lines := make([]string, max(t.height, 1))
+	for i := range lines {
+		lines[i] = blank
+	}
+	return t.alerts.Render(strings.Join(lines, "\n"))
+}
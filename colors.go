@@ -0,0 +1,177 @@
+package bubbleup
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
+)
+
+// Background colors alerts fade in from when none is configured.
+const (
+	DarkBackColor  = BackColor
+	LightBackColor = "#FFFFFF"
+)
+
+// defaultBackground is used when neither the AlertDefinition nor the
+// AlertModel specify a background color.
+var defaultBackground = lipgloss.AdaptiveColor{Light: LightBackColor, Dark: DarkBackColor}
+
+// Number of distinct steps alerts fade in with on 256 color terminals.
+const ansi256FadeLevels = 3
+
+var errNoColor = errors.New("no color given")
+
+// fadeProgress returns how far along its fade an alert at step is drawn
+// with the given color profile. A 256 color palette is too coarse for a
+// smooth fade, which would flicker between unrelated colors, so it advances
+// in a few clear steps instead. 16 color terminals skip the fade entirely.
+func fadeProgress(step float64, profile termenv.Profile) float64 {
+	switch profile {
+	case termenv.TrueColor:
+		return step
+	case termenv.ANSI256:
+		return math.Ceil(step*ansi256FadeLevels) / ansi256FadeLevels
+	default:
+		return 1
+	}
+}
+
+// resolveColor converts a lipgloss.TerminalColor into a color that can be
+// blended, using the light or dark variant of adaptive colors depending on
+// the terminal background.
+func resolveColor(c lipgloss.TerminalColor, dark bool) (colorful.Color, error) {
+	switch c := c.(type) {
+	case nil, lipgloss.NoColor:
+		return colorful.Color{}, errNoColor
+	case lipgloss.Color:
+		return parseColor(string(c))
+	case lipgloss.ANSIColor:
+		return parseColor(strconv.FormatUint(uint64(c), 10))
+	case lipgloss.AdaptiveColor:
+		if dark {
+			return parseColor(c.Dark)
+		}
+		return parseColor(c.Light)
+	case lipgloss.CompleteColor:
+		return resolveCompleteColor(c)
+	case lipgloss.CompleteAdaptiveColor:
+		if dark {
+			return resolveCompleteColor(c.Dark)
+		}
+		return resolveCompleteColor(c.Light)
+	default:
+		col, ok := colorful.MakeColor(c)
+		if !ok {
+			return colorful.Color{}, fmt.Errorf("unsupported color %v", c)
+		}
+		return col, nil
+	}
+}
+
+// resolveCompleteColor uses the most precise of the given values.
+func resolveCompleteColor(c lipgloss.CompleteColor) (colorful.Color, error) {
+	for _, value := range []string{c.TrueColor, c.ANSI256, c.ANSI} {
+		if value != "" {
+			return parseColor(value)
+		}
+	}
+	return colorful.Color{}, errNoColor
+}
+
+// parseColor parses a hex code, or an ANSI color index from 0 to 255.
+func parseColor(s string) (colorful.Color, error) {
+	if strings.HasPrefix(s, "#") {
+		return colorful.Hex(s)
+	}
+
+	index, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return colorful.Color{}, fmt.Errorf("%q is neither a hex code nor an ANSI color index", s)
+	}
+	return termenv.ConvertToRGB(termenv.ANSI256Color(index)), nil
+}
+
+// validateColor checks that c resolves on both light and dark backgrounds.
+func validateColor(c lipgloss.TerminalColor) error {
+	if _, err := resolveColor(c, true); err != nil {
+		return err
+	}
+	_, err := resolveColor(c, false)
+	return err
+}
+
+// isAdaptive reports whether c has light and dark variants, so resolving it
+// depends on the terminal background.
+func isAdaptive(c lipgloss.TerminalColor) bool {
+	switch c.(type) {
+	case lipgloss.AdaptiveColor, lipgloss.CompleteAdaptiveColor:
+		return true
+	default:
+		return false
+	}
+}
+
+// foreColor returns the color the alert type fades to. Dark reports whether
+// the terminal background is dark, and is only called for adaptive colors.
+func (d AlertDefinition) foreColor(dark func() bool) colorful.Color {
+	// Can safely discard errors because colors are validated when
+	// registering the alert definition
+	switch {
+	case d.Color != nil:
+		fore, _ := resolveColor(d.Color, isAdaptive(d.Color) && dark())
+		return fore
+	default:
+		if parsed, ok := parsedColors[d.ForeColor]; ok {
+			return parsed
+		}
+		fore, _ := colorful.Hex(d.ForeColor)
+		return fore
+	}
+}
+
+// terminalColor returns the color the alert type is drawn with once faded
+// in, as given rather than converted to RGB, so ANSI colors keep following
+// the terminal's palette. Adaptive colors are narrowed to the variant for
+// the terminal background, which dark reports.
+func (d AlertDefinition) terminalColor(dark func() bool) lipgloss.TerminalColor {
+	switch c := d.Color.(type) {
+	case nil:
+		return lipgloss.Color(d.ForeColor)
+	case lipgloss.AdaptiveColor:
+		if dark() {
+			return lipgloss.Color(c.Dark)
+		}
+		return lipgloss.Color(c.Light)
+	case lipgloss.CompleteAdaptiveColor:
+		if dark() {
+			return c.Dark
+		}
+		return c.Light
+	default:
+		return c
+	}
+}
+
+// fromColor returns the color the alert type fades in from. The
+// definition's own background takes precedence over back. Dark reports
+// whether the terminal background is dark, and is only called for adaptive
+// colors.
+func (d AlertDefinition) fromColor(back lipgloss.TerminalColor, dark func() bool) colorful.Color {
+	if d.BackColor != nil {
+		back = d.BackColor
+	}
+	if back == nil {
+		back = defaultBackground
+	}
+	from, err := resolveColor(back, isAdaptive(back) && dark())
+	if err != nil {
+		return backColor
+	}
+	return from
+}
@@ -0,0 +1,536 @@
+package bubbleup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AutoIconsName selects the icon set with DetectCapabilities when used as
+// Config.Icons.
+const AutoIconsName = "auto"
+
+// DefaultConfigDuration is how long alerts are shown when a Config doesn't
+// set a duration.
+const DefaultConfigDuration = 10 * time.Second
+
+// Config describes an AlertModel in a form that can be stored in a file,
+// so alerts can be tweaked without recompiling. Build a model from it with
+// NewAlertModelFromConfig.
+//
+// ParseConfig and LoadConfig read JSON, while ParseYAMLConfig and
+// ParseTOMLConfig read YAML and TOML. ConfigParser picks one by file name.
+type Config struct {
+	// (Req) Maximum width of alerts, see NewAlertModel
+	Width int `json:"width" yaml:"width" toml:"width"`
+
+	// (Opt) Minimum width of alerts, enabling dynamic width, see WithMinWidth
+	MinWidth int `json:"min_width,omitempty" yaml:"min_width,omitempty" toml:"min_width,omitempty"`
+
+	// (Opt) How long alerts are shown, such as "10s" or "1m30s". Defaults to
+	// DefaultConfigDuration
+	Duration Duration `json:"duration,omitempty" yaml:"duration,omitempty" toml:"duration,omitempty"`
+
+	// (Opt) Where alerts are shown, such as "top-left" or "bottom-right"
+	Position Position `json:"position,omitempty" yaml:"position,omitempty" toml:"position,omitempty"`
+
+	// (Opt) Icon set: "nerdfont", "unicode", "ascii", "emoji", or "auto" to
+	// detect one. Defaults to "ascii", or to the theme's icons
+	Icons string `json:"icons,omitempty" yaml:"icons,omitempty" toml:"icons,omitempty"`
+
+	// (Opt) Name of a preset theme, see ThemeByName
+	Theme string `json:"theme,omitempty" yaml:"theme,omitempty" toml:"theme,omitempty"`
+
+	// (Opt) Hex code or ANSI index of the color alerts fade in from
+	Background string `json:"background,omitempty" yaml:"background,omitempty" toml:"background,omitempty"`
+
+	// (Opt) Whether Esc dismisses alerts, and whether messages support markup
+	AllowEscToClose bool `json:"allow_esc_to_close,omitempty" yaml:"allow_esc_to_close,omitempty" toml:"allow_esc_to_close,omitempty"`
+	Markup          bool `json:"markup,omitempty" yaml:"markup,omitempty" toml:"markup,omitempty"`
+
+	// (Opt) Alert types to register, replacing built-in types with the same key
+	AlertTypes []AlertTypeConfig `json:"alert_types,omitempty" yaml:"alert_types,omitempty" toml:"alert_types,omitempty"`
+}
+
+// AlertTypeConfig is the serializable form of an AlertDefinition.
+type AlertTypeConfig struct {
+	// (Req) Unique key used to refer to the alert type
+	Key string `json:"key" yaml:"key" toml:"key"`
+
+	// (Req) Hex code or ANSI index of the alert's color. If DarkColor is set
+	// too, Color is used on light terminals only
+	Color     string `json:"color" yaml:"color" toml:"color"`
+	DarkColor string `json:"dark_color,omitempty" yaml:"dark_color,omitempty" toml:"dark_color,omitempty"`
+
+	// (Opt) Hex code or ANSI index of the color the alert fades in from
+	BackColor string `json:"back_color,omitempty" yaml:"back_color,omitempty" toml:"back_color,omitempty"`
+
+	// (Opt) Prefix shown before the message. Defaults to the icon set's glyph
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty" toml:"prefix,omitempty"`
+
+	// (Opt) Whether alerts of this type are mirrored as desktop notifications
+	Desktop bool `json:"desktop,omitempty" yaml:"desktop,omitempty" toml:"desktop,omitempty"`
+}
+
+// Definition returns the AlertDefinition described by the config.
+func (c AlertTypeConfig) Definition() AlertDefinition {
+	definition := AlertDefinition{
+		Key:     c.Key,
+		Color:   lipgloss.Color(c.Color),
+		Prefix:  c.Prefix,
+		Desktop: c.Desktop,
+	}
+	if c.DarkColor != "" {
+		definition.Color = lipgloss.AdaptiveColor{Light: c.Color, Dark: c.DarkColor}
+	}
+	if c.BackColor != "" {
+		definition.BackColor = lipgloss.Color(c.BackColor)
+	}
+	return definition
+}
+
+// Duration is a time.Duration written as text, such as "10s", in config files.
+type Duration time.Duration
+
+// String formats the duration like time.Duration does.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText encodes the duration like time.Duration.String.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a duration accepted by time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, must look like \"10s\" or \"1m30s\"", string(text))
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// ConfigError reports a problem with a Config, and where it is in the file
+// it was parsed from.
+type ConfigError struct {
+	// Path of the offending field, such as "alert_types[1].color". Empty for
+	// syntax errors
+	Field string
+
+	// Position in the file, counting from 1. Zero if the Config wasn't parsed
+	// from a file, or the position is unknown
+	Line, Column int
+
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	var sb strings.Builder
+	switch {
+	case e.Column > 0:
+		fmt.Fprintf(&sb, "line %d, column %d: ", e.Line, e.Column)
+	case e.Line > 0:
+		fmt.Fprintf(&sb, "line %d: ", e.Line)
+	}
+	if e.Field != "" {
+		sb.WriteString(e.Field + ": ")
+	}
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// LoadConfig reads a JSON Config from r and validates it.
+func LoadConfig(r io.Reader) (Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Config{}, err
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses a JSON Config and validates it. Errors are
+// *ConfigError values pointing at the offending line, joined together if
+// there are several problems.
+func ParseConfig(data []byte) (Config, error) {
+	var cfg Config
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, locateDecodeError(data, err)
+	}
+	if dec.More() {
+		return Config{}, locateOffset(data, "", dec.InputOffset(), errors.New("unexpected data after config"))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		index, _ := indexJSON(data)
+		return Config{}, index.locate(data, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the Config for problems, returning a *ConfigError for
+// each one, joined together.
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(field string, err error) {
+		errs = append(errs, &ConfigError{Field: field, Err: err})
+	}
+
+	if c.Width <= 0 {
+		fail("width", errors.New("must be greater than zero"))
+	}
+	if c.MinWidth < 0 {
+		fail("min_width", errors.New("must not be negative"))
+	} else if c.Width > 0 && c.MinWidth > c.Width {
+		fail("min_width", fmt.Errorf("must not exceed width (%d)", c.Width))
+	}
+	if c.Duration < 0 {
+		fail("duration", errors.New("must not be negative"))
+	}
+	if !c.Position.IsValid() && c.Position != UnspecifiedPosition {
+		fail("position", fmt.Errorf("unknown position %q", string(c.Position)))
+	}
+	if c.Icons != "" && c.Icons != AutoIconsName && iconSetByName(c.Icons) == nil {
+		fail("icons", fmt.Errorf("unknown icon set %q, must be one of %s", c.Icons, strings.Join([]string{
+			NerdFontIconsName, UnicodeIconsName, ASCIIIconsName, EmojiIconsName, AutoIconsName,
+		}, ", ")))
+	}
+	if c.Theme != "" {
+		if _, ok := ThemeByName(c.Theme); !ok {
+			fail("theme", fmt.Errorf("unknown theme %q", c.Theme))
+		}
+	}
+	if c.Background != "" {
+		if err := validateColor(lipgloss.Color(c.Background)); err != nil {
+			fail("background", err)
+		}
+	}
+
+	seen := make(map[string]bool, len(c.AlertTypes))
+	for i, alertType := range c.AlertTypes {
+		field := fmt.Sprintf("alert_types[%d]", i)
+		switch {
+		case alertType.Key == "":
+			fail(field+".key", ErrEmptyAlertKey)
+		case seen[alertType.Key]:
+			fail(field+".key", fmt.Errorf("%w: %q is listed more than once", ErrDuplicateAlertKey, alertType.Key))
+		}
+		seen[alertType.Key] = true
+
+		colors := []struct {
+			field, value string
+			required     bool
+		}{
+			{"color", alertType.Color, true},
+			{"dark_color", alertType.DarkColor, false},
+			{"back_color", alertType.BackColor, false},
+		}
+		for _, color := range colors {
+			if color.value == "" {
+				if color.required {
+					fail(field+"."+color.field, fmt.Errorf("%w: missing", ErrInvalidAlertColor))
+				}
+				continue
+			}
+			if err := validateColor(lipgloss.Color(color.value)); err != nil {
+				fail(field+"."+color.field, fmt.Errorf("%w: %v", ErrInvalidAlertColor, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// NewAlertModelFromConfig creates a new AlertModel as described by cfg,
+// returning an error if the Config is invalid.
+func NewAlertModelFromConfig(cfg Config) (*AlertModel, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := *NewAlertModel(cfg.Width, cfg.Icons == NerdFontIconsName, time.Duration(cfg.Duration))
+	m, err := m.withConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// withConfig returns a copy of m with the settings of cfg, which must be
+// valid, replacing the ones set before. Runtime state such as the active
+// alert and key bindings is kept. Alert types are registered in a clone of
+// the model's Registry, so models sharing it are unaffected.
+func (m AlertModel) withConfig(cfg Config) (AlertModel, error) {
+	m.width = cfg.Width
+	m.minWidth = 0
+	m.duration = time.Duration(cfg.Duration)
+	if m.duration == 0 {
+		m.duration = DefaultConfigDuration
+	}
+	m.position = TopLeftPosition
+	m.look = DefaultTheme().appearance()
+	m.background = nil
+	m.allowEscToClose = cfg.AllowEscToClose
+	m.useMarkup = cfg.Markup
+	m.registry = m.registry.Clone()
+
+	icons := ASCIIIcons
+	if cfg.Icons == NerdFontIconsName {
+		icons = NerdFontIcons
+	}
+	m = m.WithIconSet(icons)
+
+	if cfg.Theme != "" {
+		theme, _ := ThemeByName(cfg.Theme)
+		m = m.WithTheme(theme)
+	}
+
+	switch cfg.Icons {
+	case "", NerdFontIconsName:
+	case AutoIconsName:
+		m = m.WithAutoDetect()
+	default:
+		m = m.WithIconSet(iconSetByName(cfg.Icons))
+	}
+
+	if cfg.Position != UnspecifiedPosition {
+		m = m.WithPosition(cfg.Position)
+	}
+	if cfg.MinWidth > 0 {
+		m = m.WithMinWidth(cfg.MinWidth)
+	}
+	if cfg.Background != "" {
+		m = m.WithBackgroundColor(lipgloss.Color(cfg.Background))
+	}
+
+	for _, alertType := range cfg.AlertTypes {
+		if err := m.ReplaceAlertType(alertType.Definition()); err != nil {
+			return m, err
+		}
+		// Alert types configured alongside a theme keep their own color.
+		if _, ok := m.look.colors[alertType.Key]; ok {
+			m.look.colors = maps.Clone(m.look.colors)
+			delete(m.look.colors, alertType.Key)
+		}
+	}
+
+	return m, nil
+}
+
+// Region: locating errors
+
+// fieldLocation is where a field of a config file starts: its key, if it
+// has one, and its value, as byte offsets. Raw holds the value if it is a
+// string or a JSON token.
+type fieldLocation struct {
+	key, value int64
+	raw        any
+}
+
+// fieldIndex maps lowercased field paths, such as "alert_types[1].color",
+// to their location in the file.
+type fieldIndex map[string]fieldLocation
+
+// indexJSON walks the tokens of data, recording where every member starts.
+// It returns what it indexed so far if data is malformed.
+func indexJSON(data []byte) (fieldIndex, error) {
+	index := fieldIndex{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		start := tokenStart(data, dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		loc := index[path]
+		loc.value, loc.raw = start, tok
+		index[path] = loc
+
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				keyStart := tokenStart(data, dec.InputOffset())
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child := strings.ToLower(fmt.Sprint(key))
+				if path != "" {
+					child = path + "." + child
+				}
+				index[child] = fieldLocation{key: keyStart}
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+
+	return index, walk("")
+}
+
+// locate fills in the line and column of every *ConfigError in err.
+func (index fieldIndex) locate(data []byte, err error) error {
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else {
+		errs = []error{err}
+	}
+
+	for _, err := range errs {
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) {
+			continue
+		}
+		if loc, ok := index[strings.ToLower(cfgErr.Field)]; ok {
+			cfgErr.Line, cfgErr.Column = lineColumn(data, loc.value)
+		}
+	}
+	return err
+}
+
+// locateDecodeError turns an error from decoding data into a *ConfigError
+// pointing at its cause.
+func locateDecodeError(data []byte, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return locateOffset(data, "", syntaxErr.Offset-1, syntaxErr)
+
+	case errors.Is(err, io.EOF):
+		return &ConfigError{Err: errors.New("config is empty")}
+
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return locateOffset(data, "", int64(len(data)), errors.New("unexpected end of config"))
+
+	case errors.As(err, &typeErr):
+		// The offset points past the value, so find the member it belongs to.
+		index, _ := indexJSON(data)
+		field, loc := index.before(typeErr.Offset)
+		return locateOffset(data, field, loc.value, fmt.Errorf("cannot use %s as %s", typeErr.Value, typeErr.Type))
+	}
+
+	index, _ := indexJSON(data)
+
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name, _ = strconv.Unquote(name)
+		for _, field := range index.sorted() {
+			if field == strings.ToLower(name) || strings.HasSuffix(field, "."+strings.ToLower(name)) {
+				return locateOffset(data, field, index[field].key, errors.New("unknown field"))
+			}
+		}
+		return &ConfigError{Field: name, Err: errors.New("unknown field")}
+	}
+
+	return index.locateTextError(data, err)
+}
+
+// locateTextError turns an error returned by an UnmarshalText method,
+// without any hint of where it came from, into a *ConfigError by finding
+// the value that fails again.
+func (index fieldIndex) locateTextError(data []byte, err error) error {
+	for _, field := range index.sorted() {
+		text, ok := index[field].raw.(string)
+		if !ok {
+			continue
+		}
+		var textErr error
+		switch field[strings.LastIndex(field, ".")+1:] {
+		case "position":
+			textErr = new(Position).UnmarshalText([]byte(text))
+		case "duration":
+			textErr = new(Duration).UnmarshalText([]byte(text))
+		}
+		if textErr != nil {
+			return locateOffset(data, field, index[field].value, textErr)
+		}
+	}
+
+	return &ConfigError{Err: err}
+}
+
+// before returns the innermost member whose value starts before offset.
+func (index fieldIndex) before(offset int64) (string, fieldLocation) {
+	var (
+		field string
+		found = fieldLocation{value: -1}
+	)
+	for path, loc := range index {
+		if loc.value < offset && loc.value > found.value {
+			field, found = path, loc
+		}
+	}
+	return field, found
+}
+
+// sorted returns the paths in the index in document order.
+func (index fieldIndex) sorted() []string {
+	paths := make([]string, 0, len(index))
+	for path := range index {
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		return int(index[a].value - index[b].value)
+	})
+	return paths
+}
+
+// locateOffset returns a *ConfigError for field at the given byte offset.
+func locateOffset(data []byte, field string, offset int64, err error) *ConfigError {
+	line, column := lineColumn(data, offset)
+	return &ConfigError{Field: field, Line: line, Column: column, Err: err}
+}
+
+// tokenStart skips the whitespace and separators at offset, returning
+// where the next token starts.
+func tokenStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// lineColumn converts a byte offset into a line and column, both counting
+// from 1. Columns count characters rather than bytes.
+func lineColumn(data []byte, offset int64) (line, column int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return bytes.Count(before, []byte("\n")) + 1, utf8.RuneCount(before[lineStart:]) + 1
+}
@@ -0,0 +1,338 @@
+package bubbleup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigParser returns the function parsing config files named like path:
+// ParseYAMLConfig for .yaml and .yml files, ParseTOMLConfig for .toml files,
+// and ParseConfig for anything else.
+func ConfigParser(path string) func(data []byte) (Config, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAMLConfig
+	case ".toml":
+		return ParseTOMLConfig
+	default:
+		return ParseConfig
+	}
+}
+
+// ParseYAMLConfig parses a YAML Config and validates it, reporting errors
+// like ParseConfig. Only the first document in data is read.
+func ParseYAMLConfig(data []byte) (Config, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return Config{}, locateYAMLError(data, nil, err)
+	}
+	if len(root.Content) == 0 {
+		return Config{}, &ConfigError{Err: errors.New("config is empty")}
+	}
+
+	index := fieldIndex{}
+	index.addYAML(data, "", root.Content[0])
+
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, locateYAMLError(data, index, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, index.locate(data, err)
+	}
+
+	return cfg, nil
+}
+
+// ParseTOMLConfig parses a TOML Config and validates it, reporting errors
+// like ParseConfig.
+func ParseTOMLConfig(data []byte) (Config, error) {
+	index := indexTOML(data)
+
+	var cfg Config
+	meta, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return Config{}, locateTOMLError(data, index, err)
+	}
+
+	var errs []error
+	for _, key := range meta.Undecoded() {
+		errs = append(errs, index.unknownField(data, key...))
+	}
+	if len(errs) > 0 {
+		return Config{}, errors.Join(errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, index.locate(data, err)
+	}
+
+	return cfg, nil
+}
+
+// Region: locating YAML and TOML errors
+
+// Messages of the errors the YAML and TOML decoders only report as text.
+var (
+	yamlLineError = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlFieldName = regexp.MustCompile(`^field (.*) not found in type`)
+	tomlLineError = regexp.MustCompile(`^toml: line (\d+) \(last key "[^"]*"\): (.*)$`)
+)
+
+// addYAML records where node and everything below it starts, under path.
+func (index fieldIndex) addYAML(data []byte, path string, node *yaml.Node) {
+	loc := index[path]
+	loc.value = lineOffset(data, node.Line, node.Column)
+	if node.Kind == yaml.ScalarNode {
+		loc.raw = node.Value
+	}
+	index[path] = loc
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			child := strings.ToLower(key.Value)
+			if path != "" {
+				child = path + "." + child
+			}
+			index[child] = fieldLocation{key: lineOffset(data, key.Line, key.Column)}
+			index.addYAML(data, child, node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			index.addYAML(data, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	}
+}
+
+// locateYAMLError turns an error from decoding YAML into *ConfigError
+// values pointing at their causes. Index is nil for syntax errors.
+func locateYAMLError(data []byte, index fieldIndex, err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		if match := yamlLineError.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return &ConfigError{Line: line, Err: errors.New(match[2])}
+		}
+		if index == nil {
+			return &ConfigError{Err: err}
+		}
+		return index.locateTextError(data, err)
+	}
+
+	errs := make([]error, 0, len(typeErr.Errors))
+	for _, text := range typeErr.Errors {
+		match := yamlLineError.FindStringSubmatch(text)
+		if match == nil {
+			errs = append(errs, &ConfigError{Err: errors.New(text)})
+			continue
+		}
+		line, _ := strconv.Atoi(match[1])
+		if name := yamlFieldName.FindStringSubmatch(match[2]); name != nil {
+			lower := strings.ToLower(name[1])
+			field := index.find(data, line, func(path string, loc fieldLocation) int64 {
+				if path != lower && !strings.HasSuffix(path, "."+lower) {
+					return -1
+				}
+				return loc.key
+			})
+			errs = append(errs, locateLine(data, field, line, index[field].key, errors.New("unknown field")))
+			continue
+		}
+		field := index.find(data, line, func(_ string, loc fieldLocation) int64 { return loc.value })
+		errs = append(errs, locateLine(data, field, line, index[field].value, errors.New(match[2])))
+	}
+	return errors.Join(errs...)
+}
+
+// locateTOMLError turns an error from decoding TOML into a *ConfigError
+// pointing at its cause.
+func locateTOMLError(data []byte, index fieldIndex, err error) error {
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		// Values rejected by an UnmarshalText method are reported at the
+		// start of the line, so point at the value itself if possible.
+		line := parseErr.Position.Line
+		field := index.find(data, line, func(_ string, loc fieldLocation) int64 { return loc.value })
+		if field != "" && index[field].raw != nil {
+			return locateLine(data, field, line, index[field].value, errors.New(parseErr.Message))
+		}
+		return &ConfigError{Line: line, Column: parseErr.Position.Col, Err: errors.New(parseErr.Message)}
+	}
+
+	if match := tomlLineError.FindStringSubmatch(err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		field := index.find(data, line, func(_ string, loc fieldLocation) int64 { return loc.value })
+		return locateLine(data, field, line, index[field].value, errors.New(match[2]))
+	}
+
+	return &ConfigError{Err: err}
+}
+
+// unknownField returns a *ConfigError for the field at key, which TOML
+// gives without the indices of arrays of tables.
+func (index fieldIndex) unknownField(data []byte, key ...string) error {
+	name := strings.ToLower(strings.Join(key, "."))
+	for _, field := range index.sorted() {
+		if stripIndices(field) == name {
+			return locateOffset(data, field, index[field].key, errors.New("unknown field"))
+		}
+	}
+	return &ConfigError{Field: name, Err: errors.New("unknown field")}
+}
+
+// find returns the last field in document order that starts on line, as
+// told by offset, which returns a negative offset for fields to skip.
+func (index fieldIndex) find(data []byte, line int, offset func(path string, loc fieldLocation) int64) string {
+	var found string
+	for _, field := range index.sorted() {
+		if field == "" {
+			continue
+		}
+		at := offset(field, index[field])
+		if at < 0 {
+			continue
+		}
+		if fieldLine, _ := lineColumn(data, at); fieldLine == line {
+			found = field
+		}
+	}
+	return found
+}
+
+// locateLine returns a *ConfigError for field at the given offset, or for line
+// alone if the field wasn't found.
+func locateLine(data []byte, field string, line int, offset int64, err error) *ConfigError {
+	if field == "" {
+		return &ConfigError{Line: line, Err: err}
+	}
+	return locateOffset(data, field, offset, err)
+}
+
+// indexTOML records where the fields of a TOML document start. It reads
+// one key per line, as config files are usually written, under the last
+// table or array of tables header. Fields written any other way, such as
+// inline tables, are left out and reported without a position.
+func indexTOML(data []byte) fieldIndex {
+	var (
+		index  = fieldIndex{}
+		counts = map[string]int{}
+		table  string
+		offset int64
+	)
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		start := offset
+		offset += int64(len(line))
+
+		text := strings.TrimRight(string(line), "\r\n")
+		trimmed := strings.TrimLeft(text, " \t")
+		indent := start + int64(len(text)-len(trimmed))
+
+		switch {
+		case strings.HasPrefix(trimmed, "[["):
+			name, _, _ := strings.Cut(trimmed[2:], "]]")
+			name = tomlKey(name)
+			table = fmt.Sprintf("%s[%d]", name, counts[name])
+			counts[name]++
+			index[table] = fieldLocation{key: indent, value: indent}
+
+		case strings.HasPrefix(trimmed, "["):
+			name, _, _ := strings.Cut(trimmed[1:], "]")
+			table = tomlKey(name)
+			index[table] = fieldLocation{key: indent, value: indent}
+
+		default:
+			key, value, ok := strings.Cut(trimmed, "=")
+			if !ok || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			path := tomlKey(key)
+			if table != "" {
+				path = table + "." + path
+			}
+			valueStart := indent + int64(len(key)+1+len(value)-len(strings.TrimLeft(value, " \t")))
+			index[path] = fieldLocation{key: indent, value: valueStart, raw: tomlString(value)}
+		}
+	}
+	return index
+}
+
+// tomlKey normalizes a possibly dotted or quoted TOML key into a path.
+func tomlKey(key string) string {
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if unquoted, err := strconv.Unquote(part); err == nil {
+			part = unquoted
+		} else {
+			part = strings.Trim(part, "'")
+		}
+		parts[i] = strings.ToLower(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlString returns the text of a single line TOML string value, or nil if
+// value isn't one.
+func tomlString(value string) any {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return nil
+	}
+	switch value[0] {
+	case '"':
+		if prefix, err := strconv.QuotedPrefix(value); err == nil {
+			text, _ := strconv.Unquote(prefix)
+			return text
+		}
+	case '\'':
+		if end := strings.IndexByte(value[1:], '\''); end >= 0 {
+			return value[1 : end+1]
+		}
+	}
+	return nil
+}
+
+// stripIndices removes the array indices from a field path.
+func stripIndices(path string) string {
+	var sb strings.Builder
+	for {
+		before, after, ok := strings.Cut(path, "[")
+		sb.WriteString(before)
+		if !ok {
+			return sb.String()
+		}
+		_, path, _ = strings.Cut(after, "]")
+	}
+}
+
+// lineOffset converts a line and column, both counting from 1 and columns
+// counting characters, into a byte offset.
+func lineOffset(data []byte, line, column int) int64 {
+	var offset int
+	for ; line > 1; line-- {
+		next := bytes.IndexByte(data[offset:], '\n')
+		if next < 0 {
+			return int64(len(data))
+		}
+		offset += next + 1
+	}
+	for ; column > 1 && offset < len(data) && data[offset] != '\n'; column-- {
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+	return int64(offset)
+}
@@ -0,0 +1,171 @@
+package bubbleup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DesktopProtocol is an escape sequence terminals turn into desktop
+// notifications.
+type DesktopProtocol int
+
+const (
+	// DesktopOSC9 is understood by iTerm2, WezTerm, Ghostty, Windows Terminal
+	// and ConEmu. It has no separate title, so the title prefixes the body.
+	DesktopOSC9 DesktopProtocol = iota
+
+	// DesktopOSC777 is understood by rxvt-unicode, foot, Ghostty, Konsole
+	// and VTE based terminals with the notify extension.
+	DesktopOSC777
+
+	// DesktopKitty is kitty's OSC 99 protocol.
+	DesktopKitty
+)
+
+// DesktopOptions configures WithDesktopNotifications.
+type DesktopOptions struct {
+	// (Opt) Escape sequence used. Defaults to DesktopOSC9
+	Protocol DesktopProtocol
+
+	// (Opt) Title of notifications. Defaults to the alert type's key
+	Title string
+
+	// (Opt) Whether to notify while the terminal is focused too
+	WhenFocused bool
+
+	// (Opt) Where sequences are written. Defaults to os.Stderr
+	Output io.Writer
+
+	// (Opt) Terminal multiplexer to pass sequences through: "tmux", "screen"
+	// or "none". Detected from the environment when empty
+	Multiplexer string
+}
+
+// Terminal multiplexers accepted by DesktopOptions.Multiplexer.
+const (
+	MultiplexerTmux   = "tmux"
+	MultiplexerScreen = "screen"
+	MultiplexerNone   = "none"
+)
+
+// desktopNotifier holds the settings of WithDesktopNotifications, serializes
+// writes from concurrent commands and keeps kitty ids unique.
+type desktopNotifier struct {
+	opts   DesktopOptions
+	mu     sync.Mutex
+	nextID atomic.Uint64
+}
+
+// WithDesktopNotifications returns a new AlertModel mirroring alerts as
+// desktop notifications while the terminal is unfocused. Only alert types
+// whose AlertDefinition sets Desktop are mirrored.
+//
+// Focus is tracked through tea.FocusMsg and tea.BlurMsg, which Bubble Tea
+// only sends when the program is started with tea.WithReportFocus(). Inside
+// tmux or screen the sequences are wrapped so they pass through to the outer
+// terminal; tmux needs allow-passthrough enabled.
+func (m AlertModel) WithDesktopNotifications(opts DesktopOptions) AlertModel {
+	m.desktop = &desktopNotifier{opts: opts}
+	return m
+}
+
+// desktopCmd returns the tea.Cmd mirroring n as a desktop notification, or
+// nil if it shouldn't be.
+func (m AlertModel) desktopCmd(n *alert) tea.Cmd {
+	notifier := m.desktop
+	if n == nil || notifier == nil || !(m.blurred || notifier.opts.WhenFocused) {
+		return nil
+	}
+	if definition, ok := m.registry.Lookup(n.key); !ok || !definition.Desktop {
+		return nil
+	}
+
+	title := notifier.opts.Title
+	if title == "" {
+		title = n.key
+	}
+	body := n.plainText()
+
+	return func() tea.Msg {
+		notifier.mu.Lock()
+		defer notifier.mu.Unlock()
+
+		out := terminalOutput(notifier.opts.Output)
+		multiplexer := notifier.opts.Multiplexer
+		if multiplexer == "" {
+			multiplexer = detectMultiplexer(os.Getenv)
+		}
+		seq := desktopSequence(notifier.opts.Protocol, title, body, notifier.nextID.Add(1))
+		// Notifications are best effort, there's nothing to fall back to.
+		_, _ = io.WriteString(out, passthrough(seq, multiplexer))
+		return nil
+	}
+}
+
+// desktopSequence returns the escape sequence showing a notification.
+func desktopSequence(protocol DesktopProtocol, title, body string, id uint64) string {
+	title, body = notificationText(title), notificationText(body)
+
+	switch protocol {
+	case DesktopOSC777:
+		// Fields are separated by semicolons, which the body may contain as
+		// the last field, but the title may not.
+		title = strings.ReplaceAll(title, ";", ",")
+		return "\x1b]777;notify;" + title + ";" + body + "\a"
+
+	case DesktopKitty:
+		// The title is sent first, and the body completes the notification.
+		return fmt.Sprintf("\x1b]99;i=%d:d=0:p=title;%s\x1b\\", id, title) +
+			fmt.Sprintf("\x1b]99;i=%d:d=1:p=body;%s\x1b\\", id, body)
+
+	default:
+		return "\x1b]9;" + title + ": " + body + "\a"
+	}
+}
+
+// notificationText flattens text onto a single line and strips control
+// characters, which could end the sequence early.
+func notificationText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return -1
+		default:
+			return r
+		}
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// detectMultiplexer returns the terminal multiplexer the program runs in.
+func detectMultiplexer(getenv func(string) string) string {
+	switch {
+	case getenv("TMUX") != "":
+		return MultiplexerTmux
+	case strings.HasPrefix(getenv("TERM"), "screen"):
+		return MultiplexerScreen
+	default:
+		return MultiplexerNone
+	}
+}
+
+// passthrough wraps seq so the multiplexer forwards it to the outer terminal.
+func passthrough(seq, multiplexer string) string {
+	switch multiplexer {
+	case MultiplexerTmux:
+		// Escapes inside the passthrough are doubled.
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case MultiplexerScreen:
+		return "\x1bP" + seq + "\x1b\\"
+	default:
+		return seq
+	}
+}
@@ -0,0 +1,258 @@
+package bubbleup
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Markers used by the detail section of error alerts.
+const (
+	detailsCollapsedMarker = "▸"
+	detailsItemMarker      = "↳"
+)
+
+// errorDetail is one error of a wrapped or joined chain.
+type errorDetail struct {
+	depth int
+	text  string
+}
+
+// errorMapping raises errors it matches with the alert type under key.
+type errorMapping struct {
+	match func(error) bool
+	key   string
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// ErrorAlertCmd will construct and return the tea.Cmd needed to trigger an
+// alert for err. The top-level error's own message is shown prominently,
+// while the errors it wraps or joins are listed in a detail section that
+// can be expanded with the key set by WithDetailsKey.
+//
+// Errors are raised with the ErrorKey alert type, unless they match one of
+// the mappings added with WithErrorAs or WithErrorIs. Returns nil if err is nil.
+func (m AlertModel) ErrorAlertCmd(err error) tea.Cmd {
+	if err == nil {
+		return nil
+	}
+
+	msg := m.errorAlertMsg(err)
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// errorAlertMsg builds the alertMsg raised for err.
+func (m AlertModel) errorAlertMsg(err error) alertMsg {
+	key := ErrorKey
+	for _, mapping := range m.errorMappings {
+		if mapping.match(err) {
+			key = mapping.key
+			break
+		}
+	}
+
+	// Wrappers without a message of their own, such as fmt.Errorf("%w", err),
+	// hand the headline to the error they wrap, whose own children then
+	// make up the details, so its text isn't repeated below.
+	headline := ownErrorMessage(err)
+	for headline == "" {
+		children := unwrapAll(err)
+		if len(children) != 1 || children[0] == nil {
+			break
+		}
+		err = children[0]
+		headline = ownErrorMessage(err)
+	}
+	details := errorChain(err, 0, nil)
+	if headline == "" {
+		headline = fmt.Sprintf("%d errors", len(unwrapAll(err)))
+		if len(unwrapAll(err)) == 1 {
+			headline = err.Error()
+		}
+	}
+
+	return alertMsg{alertKey: key, msg: headline, dur: m.duration, details: details}
+}
+
+// WithErrorAs maps errors to the alert type registered under key when
+// errors.As(err, target) succeeds. Target must be a non-nil pointer to a type
+// implementing error, or to an interface type, for example:
+//
+//	m.WithErrorAs(new(*fs.PathError), "FileSystem")
+//
+// Mappings are tried in the order they were added.
+func (m AlertModel) WithErrorAs(target any, key string) AlertModel {
+	typ := reflect.TypeOf(target)
+	if typ == nil || typ.Kind() != reflect.Pointer {
+		panic("bubbleup: WithErrorAs target must be a non-nil pointer")
+	}
+	if elem := typ.Elem(); elem.Kind() != reflect.Interface && !elem.Implements(errorType) {
+		panic("bubbleup: WithErrorAs target must point to an interface or a type implementing error")
+	}
+
+	match := func(err error) bool {
+		return errors.As(err, reflect.New(typ.Elem()).Interface())
+	}
+
+	m.errorMappings = append(slices.Clip(m.errorMappings), errorMapping{match: match, key: key})
+	return m
+}
+
+// WithErrorIs maps errors to the alert type registered under key when
+// errors.Is(err, target) succeeds. Mappings are tried in the order they were added.
+func (m AlertModel) WithErrorIs(target error, key string) AlertModel {
+	match := func(err error) bool {
+		return errors.Is(err, target)
+	}
+
+	m.errorMappings = append(slices.Clip(m.errorMappings), errorMapping{match: match, key: key})
+	return m
+}
+
+// Alertable can be implemented by messages that should raise an alert when
+// they reach an AlertModel with WithAutoAlerts enabled.
+type Alertable interface {
+	// AlertKey returns the key of the registered alert type to raise
+	AlertKey() string
+
+	// AlertMessage returns the message to display
+	AlertMessage() string
+}
+
+// WithAutoAlerts makes Update raise an alert for every message implementing
+// Alertable, and for every message implementing error as if it was passed to
+// ErrorAlertCmd. This lets commands that fail by returning an error surface
+// it without each model handling it. Alertable takes precedence for messages
+// implementing both.
+func (m AlertModel) WithAutoAlerts() AlertModel {
+	m.autoAlerts = true
+	return m
+}
+
+// interceptAlert returns the alert to raise for msg when auto alerts are enabled.
+func (m AlertModel) interceptAlert(msg tea.Msg) (alertMsg, bool) {
+	if !m.autoAlerts {
+		return alertMsg{}, false
+	}
+
+	switch msg := msg.(type) {
+	case Alertable:
+		return alertMsg{alertKey: msg.AlertKey(), msg: msg.AlertMessage(), dur: m.duration}, true
+	case error:
+		return m.errorAlertMsg(msg), true
+	}
+	return alertMsg{}, false
+}
+
+// WithDetailsKey binds a key that expands and collapses the detail section
+// of alerts raised with ErrorAlertCmd.
+func (m AlertModel) WithDetailsKey(key string) AlertModel {
+	m.detailsKey = key
+	return m
+}
+
+// unwrapAll returns the errors directly wrapped or joined by err.
+func unwrapAll(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	case interface{ Unwrap() error }:
+		if child := u.Unwrap(); child != nil {
+			return []error{child}
+		}
+	}
+	return nil
+}
+
+// ownErrorMessage returns the part of err's message that is not repeated
+// from the errors it wraps, e.g. "load config" for "load config: EOF".
+// Joined errors have no message of their own.
+func ownErrorMessage(err error) string {
+	msg := err.Error()
+	children := unwrapAll(err)
+
+	switch len(children) {
+	case 0:
+		return msg
+	case 1:
+		if msg == children[0].Error() {
+			// Wrappers that only add context, not text, have nothing to show.
+			return ""
+		}
+		if own, ok := strings.CutSuffix(msg, ": "+children[0].Error()); ok {
+			return own
+		}
+		return msg
+	default:
+		texts := make([]string, 0, len(children))
+		for _, child := range children {
+			if child != nil {
+				texts = append(texts, child.Error())
+			}
+		}
+		if msg == strings.Join(texts, "\n") {
+			return ""
+		}
+		return msg
+	}
+}
+
+// errorChain flattens the errors below err, each nested one level deeper
+// than the error that wraps or joins it.
+func errorChain(err error, depth int, out []errorDetail) []errorDetail {
+	for _, child := range unwrapAll(err) {
+		if child == nil {
+			continue
+		}
+		childDepth := depth
+		if text := ownErrorMessage(child); text != "" {
+			out = append(out, errorDetail{depth: depth, text: text})
+			childDepth++
+		}
+		out = errorChain(child, childDepth, out)
+	}
+	return out
+}
+
+// renderDetails renders the detail section of an error alert, indented to
+// line up with the message after the prefix.
+func (n *alert) renderDetails(indentW, textWidth int, style lipgloss.Style) string {
+	avail := max(textWidth-indentW, 1)
+
+	var lines []string
+	if !n.expanded {
+		hint := fmt.Sprintf("%d more", len(n.details))
+		if n.detailsKey != "" {
+			hint += fmt.Sprintf(", %s to expand", n.detailsKey)
+		}
+		lines = strings.Split(hangingWrap(detailsCollapsedMarker, hint, avail), "\n")
+	} else {
+		for _, detail := range n.details {
+			marker := strings.Repeat("  ", detail.depth) + detailsItemMarker
+			lines = append(lines, strings.Split(hangingWrap(marker, detail.text, avail), "\n")...)
+		}
+	}
+
+	indent := strings.Repeat(" ", indentW)
+	for i, line := range lines {
+		lines[i] = indent + style.Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detailsText returns the detail section as plain text, one error per line.
+func (n *alert) detailsText() string {
+	lines := make([]string, 0, len(n.details))
+	for _, detail := range n.details {
+		lines = append(lines, strings.Repeat("  ", detail.depth)+detail.text)
+	}
+	return strings.Join(lines, "\n")
+}
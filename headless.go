@@ -0,0 +1,125 @@
+package bubbleup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// RenderAlert renders an alert of the alert type registered under key, as
+// it appears in an AlertModel once faded in, for programs that don't run
+// Bubble Tea. Alerts are width cells wide, styled by theme, and use 24-bit
+// colors. Returns an error if key isn't one of the included alert types.
+func RenderAlert(key, message string, width int, theme Theme) (string, error) {
+	return NewAlertModel(width, false, 0).
+		WithTheme(theme).
+		WithColorProfile(termenv.TrueColor).
+		RenderAlert(key, message)
+}
+
+// RenderAlert renders an alert of the alert type registered under key with
+// the model's settings, as it appears once faded in, without raising it.
+// Returns an error if no alert type is registered under key.
+func (m AlertModel) RenderAlert(key, message string) (string, error) {
+	m.look.lerpStart = 1
+	n := m.newAlert(alertMsg{alertKey: key, msg: message})
+	if n == nil {
+		return "", fmt.Errorf("%w: %q", errUnknownAlertKey, key)
+	}
+	return n.render(), nil
+}
+
+// AlertWriter prints alerts one after another, for command line tools that
+// want the look of BubbleUp alerts without running Bubble Tea. When its
+// output isn't a terminal, such as when redirected to a file, each alert is
+// printed as a single line of plain text instead, like "Error: message".
+// An AlertWriter is safe for concurrent use.
+type AlertWriter struct {
+	out      io.Writer
+	model    AlertModel
+	terminal bool
+	mu       sync.Mutex
+}
+
+// NewAlertWriter returns an AlertWriter printing alerts styled by m to out,
+// or to os.Stderr if out is nil. Alerts use the color profile of out, unless
+// m was given one with WithColorProfile. With WithAutoDetect, the terminal
+// is detected again for out, since it was detected for stdout before.
+func NewAlertWriter(out io.Writer, m AlertModel) *AlertWriter {
+	out = terminalOutput(out)
+
+	w := &AlertWriter{out: out, model: m}
+	if f, ok := out.(interface{ Fd() uintptr }); ok {
+		w.terminal = term.IsTerminal(int(f.Fd()))
+	}
+	if !w.terminal {
+		return w
+	}
+
+	output := termenv.NewOutput(out)
+	switch {
+	case m.capabilities != nil:
+		caps := detectCapabilities(os.Getenv, output.EnvColorProfile())
+		w.model.capabilities = &caps
+		w.model.renderer = outputRenderer(out, caps.ColorProfile)
+	case m.renderer == nil:
+		w.model.renderer = outputRenderer(out, output.EnvColorProfile())
+	}
+	return w
+}
+
+// outputRenderer returns a renderer for out, producing colors for the given
+// profile.
+func outputRenderer(out io.Writer, p termenv.Profile) *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(out)
+	r.SetColorProfile(p)
+	return r
+}
+
+// Send prints an alert of the alert type registered under key.
+func (w *AlertWriter) Send(key, message string) error {
+	var text string
+	if w.terminal {
+		rendered, err := w.model.RenderAlert(key, message)
+		if err != nil {
+			return err
+		}
+		text = rendered + "\n"
+	} else {
+		if _, ok := w.model.AlertType(key); !ok {
+			return fmt.Errorf("%w: %q", errUnknownAlertKey, key)
+		}
+		prefix, _ := SpokenIcons.Icon(key)
+		text = prefix + " " + notificationText(message) + "\n"
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := io.WriteString(w.out, text)
+	return err
+}
+
+// Info prints an alert with the InfoKey alert type.
+func (w *AlertWriter) Info(message string) error {
+	return w.Send(InfoKey, message)
+}
+
+// Warn prints an alert with the WarnKey alert type.
+func (w *AlertWriter) Warn(message string) error {
+	return w.Send(WarnKey, message)
+}
+
+// Error prints an alert with the ErrorKey alert type.
+func (w *AlertWriter) Error(message string) error {
+	return w.Send(ErrorKey, message)
+}
+
+// Debug prints an alert with the DebugKey alert type.
+func (w *AlertWriter) Debug(message string) error {
+	return w.Send(DebugKey, message)
+}
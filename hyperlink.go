@@ -0,0 +1,43 @@
+package bubbleup
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// hyperlinkURL turns a link target into a URL suitable for OSC 8. Targets
+// that already carry a scheme are used as-is, while anything else is treated
+// as a file path and turned into an absolute file:// URL. The host name is
+// included so terminals can tell local files from ones on a remote machine.
+func hyperlinkURL(target string) string {
+	// Single letter schemes are Windows drive letters, not URLs.
+	if u, err := url.Parse(target); err == nil && len(u.Scheme) > 1 {
+		return target
+	}
+
+	path, err := filepath.Abs(target)
+	if err != nil {
+		path = target
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	host, _ := os.Hostname()
+	return (&url.URL{Scheme: "file", Host: host, Path: path}).String()
+}
+
+// hyperlinkLines wraps every line of s in its own OSC 8 hyperlink, so each
+// line stays a complete link once it is cut and overlaid onto the content.
+func hyperlinkLines(s, uri string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = ansi.SetHyperlink(uri) + line + ansi.ResetHyperlink()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+package bubbleup
+
+// Symbols used by the EmojiIcons set.
+const (
+	InfoEmoji  = "💬"
+	WarnEmoji  = "🚧"
+	ErrorEmoji = "❌"
+	DebugEmoji = "🐛"
+)
+
+// IconSet maps alert keys to the glyphs used to prefix their messages.
+// Alert types registered without a Prefix get theirs from the model's
+// IconSet, so switching sets with WithIconSet restyles them all at once.
+type IconSet interface {
+	// Icon returns the glyph for the alert type registered under key, or
+	// false if the set has no glyph for it.
+	Icon(key string) (string, bool)
+}
+
+// IconMap is an IconSet backed by a map from alert key to glyph, handy for
+// defining icons for your own alert types.
+type IconMap map[string]string
+
+// Icon returns the glyph mapped to key.
+func (s IconMap) Icon(key string) (string, bool) {
+	icon, ok := s[key]
+	return icon, ok
+}
+
+// Icon sets for the included alert types.
+var (
+	// NerdFontIcons requires a NerdFont, see https://www.nerdfonts.com/.
+	NerdFontIcons IconSet = IconMap{
+		InfoKey:  InfoNerdSymbol,
+		WarnKey:  WarnNerdSymbol,
+		ErrorKey: ErrorNerdSymbol,
+		DebugKey: DebugNerdSymbol,
+	}
+
+	// UnicodeIcons works in most terminals without a special font.
+	UnicodeIcons IconSet = IconMap{
+		InfoKey:  InfoUnicodePrefix,
+		WarnKey:  WarningUnicodePrefix,
+		ErrorKey: ErrorUnicodePrefix,
+		DebugKey: DebugUnicodePrefix,
+	}
+
+	// ASCIIIcons works everywhere, even in restricted environments.
+	ASCIIIcons IconSet = IconMap{
+		InfoKey:  InfoASCIIPrefix,
+		WarnKey:  WarningASCIIPrefix,
+		ErrorKey: ErrorASCIIPrefix,
+		DebugKey: DebugASCIIPrefix,
+	}
+
+	// EmojiIcons requires a terminal and font that render color emoji.
+	EmojiIcons IconSet = IconMap{
+		InfoKey:  InfoEmoji,
+		WarnKey:  WarnEmoji,
+		ErrorKey: ErrorEmoji,
+		DebugKey: DebugEmoji,
+	}
+)
+
+// layeredIconSet looks a key up in each of its sets in turn.
+type layeredIconSet []IconSet
+
+func (l layeredIconSet) Icon(key string) (string, bool) {
+	for _, set := range l {
+		if icon, ok := set.Icon(key); ok {
+			return icon, true
+		}
+	}
+	return "", false
+}
+
+// LayerIconSets returns an IconSet that looks keys up in each of the given
+// sets in order, using the first glyph found. Put your own sets first to add
+// icons for custom alert types on top of a built-in set:
+//
+//	LayerIconSets(IconMap{"Deploy": "🚀"}, UnicodeIcons)
+func LayerIconSets(sets ...IconSet) IconSet {
+	return layeredIconSet(sets)
+}
+
+// prefixFor returns the prefix for an alert type: its own Prefix if set,
+// otherwise the glyph the icon set has for its key.
+func prefixFor(definition AlertDefinition, icons IconSet) string {
+	if definition.Prefix != "" || icons == nil {
+		return definition.Prefix
+	}
+	icon, _ := icons.Icon(definition.Key)
+	return icon
+}
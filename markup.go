@@ -0,0 +1,426 @@
+package bubbleup
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Markup supported in alert messages once enabled with AlertModel.WithMarkup:
+//
+//	**bold**, *italic* or _italic_, `code`
+//	"- item" or "* item" at the start of a line renders a bullet item
+//	"key:: value" renders a key/value row, aligned with its neighbours
+//
+// Any markup character can be escaped with a backslash. Markers without a
+// matching closer are rendered literally.
+
+// Characters that can be escaped with a backslash.
+const markupEscapable = "*_`\\:-"
+
+// Glyph used for bullet list items, including its trailing space.
+const bulletGlyph = "• "
+
+// Space between the key column and the value column of a key/value row.
+const keyValueGap = "  "
+
+type spanFlags uint8
+
+const (
+	spanBold spanFlags = 1 << iota
+	spanItalic
+	spanCode
+)
+
+// span is a run of text sharing the same inline styling.
+type span struct {
+	text  string
+	flags spanFlags
+}
+
+type blockKind int
+
+const (
+	blockText blockKind = iota
+	blockBullet
+	blockKeyValue
+)
+
+// block is a single source line of a message, classified by kind.
+type block struct {
+	kind  blockKind
+	key   string
+	spans []span
+}
+
+// parseMarkup splits a message into blocks, one per source line.
+func parseMarkup(msg string) []block {
+	lines := strings.Split(msg, "\n")
+	blocks := make([]block, 0, len(lines))
+
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+
+		switch {
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			blocks = append(blocks, block{
+				kind:  blockBullet,
+				spans: parseInline(strings.TrimSpace(trimmed[2:])),
+			})
+
+		case keyValueIndex(trimmed) > 0:
+			idx := keyValueIndex(trimmed)
+			blocks = append(blocks, block{
+				kind:  blockKeyValue,
+				key:   strings.TrimSpace(trimmed[:idx]),
+				spans: parseInline(strings.TrimSpace(trimmed[idx+2:])),
+			})
+
+		default:
+			blocks = append(blocks, block{
+				kind:  blockText,
+				spans: parseInline(line),
+			})
+		}
+	}
+
+	return blocks
+}
+
+// keyValueIndex returns the index of the "::" separating a key from its value,
+// or -1 if the line is not a key/value row.
+func keyValueIndex(line string) int {
+	idx := strings.Index(line, "::")
+	if idx <= 0 || line[idx-1] == '\\' {
+		return -1
+	}
+	rest := line[idx+2:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return -1
+	}
+	return idx
+}
+
+// parseInline splits a line into styled spans.
+func parseInline(s string) []span {
+	var (
+		spans        []span
+		cur          strings.Builder
+		flags        spanFlags
+		italicMarker byte
+	)
+
+	flush := func() {
+		if cur.Len() > 0 {
+			spans = append(spans, span{text: cur.String(), flags: flags})
+			cur.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c == '\\' && i+1 < len(s) && strings.IndexByte(markupEscapable, s[i+1]) >= 0 {
+			cur.WriteByte(s[i+1])
+			i += 2
+			continue
+		}
+
+		if c == '`' {
+			if flags&spanCode != 0 {
+				flush()
+				flags &^= spanCode
+				i++
+				continue
+			}
+			if strings.IndexByte(s[i+1:], '`') >= 0 {
+				flush()
+				flags |= spanCode
+				i++
+				continue
+			}
+		}
+
+		if flags&spanCode != 0 {
+			cur.WriteByte(c)
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(s[i:], "**") {
+			if flags&spanBold != 0 {
+				flush()
+				flags &^= spanBold
+				i += 2
+				continue
+			}
+			if canOpen(s, i, 2) && strings.Contains(s[i+2:], "**") {
+				flush()
+				flags |= spanBold
+				i += 2
+				continue
+			}
+		}
+
+		if c == '*' || c == '_' {
+			if flags&spanItalic != 0 && c == italicMarker && canClose(s, i, 1) {
+				flush()
+				flags &^= spanItalic
+				i++
+				continue
+			}
+			if flags&spanItalic == 0 && canOpen(s, i, 1) && hasCloser(s, i+1, c) {
+				flush()
+				flags |= spanItalic
+				italicMarker = c
+				i++
+				continue
+			}
+		}
+
+		cur.WriteByte(c)
+		i++
+	}
+	flush()
+
+	return spans
+}
+
+// canOpen reports whether the marker of length n at s[i] can start a span:
+// it must not be glued to a preceding word, and must be followed by text.
+func canOpen(s string, i, n int) bool {
+	if i > 0 && isWordByte(s[i-1]) {
+		return false
+	}
+	return i+n < len(s) && s[i+n] != ' '
+}
+
+// canClose reports whether the marker of length n at s[i] can end a span.
+func canClose(s string, i, n int) bool {
+	if i == 0 || s[i-1] == ' ' {
+		return false
+	}
+	return i+n >= len(s) || !isWordByte(s[i+n])
+}
+
+// hasCloser reports whether a closing marker c appears in s from index start.
+func hasCloser(s string, start int, c byte) bool {
+	for j := start; j < len(s); j++ {
+		if s[j] == c && canClose(s, j, 1) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b >= 0x80
+}
+
+// renderMarkup lays out the blocks within textWidth cells with the prefix
+// hanging in front of the first line, mirroring hangingWrap. Every piece of
+// text is rendered through base so the alert's color survives the resets
+// emitted by the inline styles.
+func renderMarkup(prefix string, blocks []block, textWidth int, base lipgloss.Style) string {
+	prefix = prefix + " "
+	indentW := lipgloss.Width(prefix)
+	avail := textWidth - indentW
+	if avail < 1 {
+		// Degenerate case: not enough room for message; just show prefix and text raw.
+		return prefix + plainMarkup(blocks, " ")
+	}
+
+	keyWidth := 0
+	for _, b := range blocks {
+		if b.kind == blockKeyValue {
+			keyWidth = max(keyWidth, runewidth.StringWidth(b.key))
+		}
+	}
+
+	var lines []string
+	for _, b := range blocks {
+		switch b.kind {
+		case blockBullet:
+			lines = append(lines, hangLines(
+				base.Render(bulletGlyph),
+				wrapSpans(b.spans, avail-runewidth.StringWidth(bulletGlyph), base),
+			)...)
+
+		case blockKeyValue:
+			valueWidth := avail - keyWidth - len(keyValueGap)
+			key := runewidth.FillRight(b.key, keyWidth)
+			if valueWidth < 1 {
+				// Not enough room for columns, so fall back to a plain line.
+				lines = append(lines, wrapSpans(append([]span{{text: b.key + ": "}}, b.spans...), avail, base)...)
+				break
+			}
+			lines = append(lines, hangLines(
+				base.Bold(true).Render(key)+keyValueGap,
+				wrapSpans(b.spans, valueWidth, base),
+			)...)
+
+		default:
+			lines = append(lines, wrapSpans(b.spans, avail, base)...)
+		}
+	}
+
+	indent := strings.Repeat(" ", indentW)
+	for i := 1; i < len(lines); i++ {
+		lines[i] = indent + lines[i]
+	}
+
+	return prefix + strings.Join(lines, "\n")
+}
+
+// markupWidth returns the width of the widest line of the blocks when they
+// are laid out without wrapping.
+func markupWidth(prefix string, blocks []block) int {
+	_, widest := getLines(renderMarkup(prefix, blocks, math.MaxInt32, lipgloss.NewStyle()))
+	return widest
+}
+
+// hangLines puts head in front of the first line and indents the rest to match.
+func hangLines(head string, lines []string) []string {
+	indent := strings.Repeat(" ", lipgloss.Width(head))
+	for i := range lines {
+		if i == 0 {
+			lines[i] = head + lines[i]
+		} else {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return lines
+}
+
+// piece is the part of a word that falls within a single span.
+type piece struct {
+	text  string
+	flags spanFlags
+}
+
+// wrapSpans greedily wraps spans to width, breaking on spaces and hard
+// breaking words that do not fit on a line of their own.
+func wrapSpans(spans []span, width int, base lipgloss.Style) []string {
+	width = max(width, 1)
+
+	var (
+		lines   []string
+		line    strings.Builder
+		lineW   int
+		lastFlg spanFlags
+	)
+
+	emit := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		lineW = 0
+	}
+
+	for _, word := range splitWords(spans) {
+		wordW := 0
+		for _, p := range word {
+			wordW += runewidth.StringWidth(p.text)
+		}
+
+		if lineW > 0 && lineW+1+wordW > width {
+			emit()
+		}
+		if lineW > 0 {
+			// Keep code spans contiguous across the space between words.
+			gap := lastFlg & word[0].flags & spanCode
+			line.WriteString(styleFor(base, gap).Render(" "))
+			lineW++
+		}
+
+		for _, p := range word {
+			text := p.text
+			for text != "" {
+				room := width - lineW
+				if room < 1 {
+					emit()
+					room = width
+				}
+				head := runewidth.Truncate(text, room, "")
+				if head == "" {
+					// A single rune wider than the line; place it anyway.
+					_, size := utf8.DecodeRuneInString(text)
+					head = text[:size]
+				}
+				line.WriteString(styleFor(base, p.flags).Render(head))
+				lineW += runewidth.StringWidth(head)
+				text = text[len(head):]
+			}
+			lastFlg = p.flags
+		}
+	}
+
+	if lineW > 0 || len(lines) == 0 {
+		emit()
+	}
+
+	return lines
+}
+
+// splitWords breaks spans into space-separated words, each made of one or
+// more pieces with their own styling.
+func splitWords(spans []span) [][]piece {
+	var (
+		words [][]piece
+		word  []piece
+	)
+
+	for _, s := range spans {
+		parts := strings.Split(s.text, " ")
+		for i, part := range parts {
+			if i > 0 && len(word) > 0 {
+				words = append(words, word)
+				word = nil
+			}
+			if part != "" {
+				word = append(word, piece{text: part, flags: s.flags})
+			}
+		}
+	}
+	if len(word) > 0 {
+		words = append(words, word)
+	}
+
+	return words
+}
+
+// styleFor returns base with the inline styles described by flags applied.
+func styleFor(base lipgloss.Style, flags spanFlags) lipgloss.Style {
+	if flags&spanBold != 0 {
+		base = base.Bold(true)
+	}
+	if flags&spanItalic != 0 {
+		base = base.Italic(true)
+	}
+	if flags&spanCode != 0 {
+		base = base.Reverse(true)
+	}
+	return base
+}
+
+// plainMarkup flattens blocks back into unstyled text, one block per part
+// joined by sep.
+func plainMarkup(blocks []block, sep string) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		var sb strings.Builder
+		switch b.kind {
+		case blockBullet:
+			sb.WriteString("- ")
+		case blockKeyValue:
+			sb.WriteString(b.key + ": ")
+		}
+		for _, s := range b.spans {
+			sb.WriteString(s.text)
+		}
+		parts = append(parts, sb.String())
+	}
+	return strings.Join(parts, sep)
+}
@@ -1,11 +1,13 @@
 package bubbleup
 
 import (
+	"io"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/muesli/reflow/ansi"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // AlertModel maintains a list of alert types, and facilitates the display and
@@ -15,33 +17,54 @@ import (
 //   - minWidth == 0 (default): width is fixed width
 //   - minWidth > 0: width is max width, minWidth is minimum, actual width varies with message length
 type AlertModel struct {
-	useNerdFont      bool
-	useUnicodePrefix bool
-	allowEscToClose  bool
-	alertTypes       map[string]AlertDefinition
-	activeAlert      *alert
-	width            int
-	minWidth         int
-	duration         time.Duration
-	position         Position
+	iconSet         IconSet
+	capabilities    *Capabilities
+	renderer        *lipgloss.Renderer
+	background      lipgloss.TerminalColor
+	darkBackground  *bool
+	look            appearance
+	blurred         bool
+	allowEscToClose bool
+	useMarkup       bool
+	copyKey         string
+	detailsKey      string
+	errorMappings   []errorMapping
+	autoAlerts      bool
+	clipboardOut    io.Writer
+	registry        *Registry
+	activeAlert     *alert
+	width           int
+	minWidth        int
+	duration        time.Duration
+	position        Position
+
+	// State shared between the copies of the model Update returns, so
+	// writes stay serialized and counters consistent
+	accessibility *accessibility
+	desktop       *desktopNotifier
+	limiter       *rateLimiter
 }
 
 // TODO: Set defaults for duration
 
 // NewAlertModel creates and returns a new AlertModel, initialized with default alert types
 func NewAlertModel(width int, useNerdFont bool, duration time.Duration) *AlertModel {
+	iconSet := ASCIIIcons
+	if useNerdFont {
+		iconSet = NerdFontIcons
+	}
+
 	model := &AlertModel{
 		activeAlert: nil,
 		width:       width,
 		minWidth:    0,
-		useNerdFont: useNerdFont,
-		alertTypes:  make(map[string]AlertDefinition),
+		iconSet:     iconSet,
+		registry:    NewDefaultRegistry(),
 		duration:    duration,
 		position:    TopLeftPosition,
+		look:        DefaultTheme().appearance(),
 	}
 
-	model.registerDefaultAlertTypes()
-
 	return model
 }
 
@@ -64,24 +87,141 @@ func (m AlertModel) WithMinWidth(min int) AlertModel {
 	return m
 }
 
-// WithUnicodePrefix switches the AlertModule to use Unicode fonts
+// WithUnicodePrefix switches the AlertModule to use Unicode fonts.
+// Shorthand for WithIconSet(UnicodeIcons).
 func (m AlertModel) WithUnicodePrefix() AlertModel {
-	m.useNerdFont = false
-	m.useUnicodePrefix = true //
-	alertTypes := make(map[string]AlertDefinition, len(m.alertTypes))
-	for name, alertType := range m.alertTypes {
-		alertType.Prefix = unicodePrefixes[alertType.Key]
-		alertTypes[name] = alertType
+	return m.WithIconSet(UnicodeIcons)
+}
+
+// WithIconSet returns a new AlertModel prefixing alerts with glyphs from the
+// given IconSet. Only alert types registered without a Prefix of their own
+// are affected, so custom definitions keep their prefixes. Can be called at
+// any time, including while an alert is displayed.
+func (m AlertModel) WithIconSet(icons IconSet) AlertModel {
+	m.iconSet = icons
+	if m.activeAlert != nil {
+		if definition, ok := m.registry.Lookup(m.activeAlert.key); ok {
+			updated := *m.activeAlert
+			updated.prefix = prefixFor(definition, icons)
+			m.activeAlert = &updated
+		}
+	}
+	return m
+}
+
+// WithAutoDetect returns a new AlertModel using the icon set and color
+// profile chosen by DetectCapabilities, instead of guessing whether a
+// NerdFont is installed. Use Capabilities to display or log the decision.
+func (m AlertModel) WithAutoDetect() AlertModel {
+	caps := DetectCapabilities()
+	m.capabilities = &caps
+	m.renderer = rendererFor(caps.ColorProfile)
+	return m.WithIconSet(caps.Icons)
+}
+
+// WithColorProfile returns a new AlertModel rendering alerts for the given
+// color profile, instead of the one detected for the terminal. Alerts fade
+// in smoothly with TrueColor, in a few steps with ANSI256, appear at full
+// color with ANSI, and are drawn without colors with Ascii.
+func (m AlertModel) WithColorProfile(p termenv.Profile) AlertModel {
+	m.renderer = rendererFor(p)
+	return m
+}
+
+// colorProfile returns the color profile alerts are rendered with.
+func (m AlertModel) colorProfile() termenv.Profile {
+	if m.renderer != nil {
+		return m.renderer.ColorProfile()
+	}
+	return lipgloss.ColorProfile()
+}
+
+// Capabilities returns what WithAutoDetect decided about the terminal, and
+// false if auto detection wasn't used.
+func (m AlertModel) Capabilities() (Capabilities, bool) {
+	if m.capabilities == nil {
+		return Capabilities{}, false
 	}
-	m.alertTypes = alertTypes
+	return *m.capabilities, true
+}
+
+// WithBackgroundColor returns a new AlertModel whose alerts fade in from the
+// given color, unless their AlertDefinition sets a BackColor of its own.
+// Use a lipgloss.AdaptiveColor to pick a color for light and dark terminals.
+// Defaults to black on dark terminals and white on light ones.
+func (m AlertModel) WithBackgroundColor(c lipgloss.TerminalColor) AlertModel {
+	m.background = c
+	return m
+}
+
+// WithDarkBackground overrides whether the terminal has a dark background,
+// which decides the variant of adaptive colors used. By default this is
+// detected the first time an adaptive color is resolved, by querying the
+// terminal through lipgloss like adaptive colors in lipgloss styles are.
+// Set it to avoid the query, such as when the answer is already known.
+func (m AlertModel) WithDarkBackground(dark bool) AlertModel {
+	m.darkBackground = &dark
+	return m
+}
+
+// hasDarkBackground reports whether the terminal has a dark background,
+// only querying it if WithDarkBackground wasn't used. Lipgloss caches the
+// answer, so the terminal is queried once at most.
+func (m AlertModel) hasDarkBackground() bool {
+	if m.darkBackground != nil {
+		return *m.darkBackground
+	}
+	if m.renderer != nil {
+		return m.renderer.HasDarkBackground()
+	}
+	return lipgloss.HasDarkBackground()
+}
+
+// IconSet returns the IconSet the model prefixes alerts with.
+func (m AlertModel) IconSet() IconSet {
+	return m.iconSet
+}
+
+// WithRegistry returns a new AlertModel using the given Registry for its
+// alert types. Pass the same Registry to several models to share alert types
+// between them, or a Registry's Clone to give a model its own copy.
+func (m AlertModel) WithRegistry(r *Registry) AlertModel {
+	m.registry = r
 	return m
 }
 
+// Registry returns the Registry holding the model's alert types.
+func (m AlertModel) Registry() *Registry {
+	return m.registry
+}
+
 func (m AlertModel) WithAllowEscToClose() AlertModel {
 	m.allowEscToClose = true
 	return m
 }
 
+// WithMarkup enables lightweight markup in alert messages: **bold**,
+// *italic* or _italic_, `code`, "- " bullet items, and "key:: value" rows
+// whose keys are aligned with each other.
+func (m AlertModel) WithMarkup() AlertModel {
+	m.useMarkup = true
+	return m
+}
+
+// WithCopyKey binds a key, such as "y" or "ctrl+y", that copies the active
+// alert's message to the system clipboard. See CopyAlertCmd for details.
+func (m AlertModel) WithCopyKey(key string) AlertModel {
+	m.copyKey = key
+	return m
+}
+
+// WithClipboardOutput sets where the clipboard escape sequence is written.
+// Defaults to os.Stderr.
+func (m AlertModel) WithClipboardOutput(w io.Writer) AlertModel {
+	m.clipboardOut = w
+	return m
+}
+
 // Init required as part of BubbleTea Model interface
 func (m AlertModel) Init() tea.Cmd {
 	return nil
@@ -94,8 +234,18 @@ func (m AlertModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case alertMsg:
-		m.activeAlert = m.newAlert(msg.alertKey, msg.msg, msg.dur)
-		return m, tickCmd() // Start ticking when new alert appears
+		return m.raise(msg)
+
+	case rateSummaryMsg:
+		if msg.limiter != m.limiter {
+			break
+		}
+		if summary, ok := m.limiter.summary(); ok {
+			return m.raise(summary)
+		}
+
+	case configReloadMsg:
+		return m.reloadConfig(msg)
 
 	case tickMsg: // Check to see if it's time to clear the alert
 		if m.activeAlert == nil {
@@ -108,7 +258,7 @@ func (m AlertModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			break
 		}
 		// Keep ticking while alert is active
-		m.activeAlert.curLerpStep += DefaultLerpIncrement
+		m.activeAlert.curLerpStep += m.activeAlert.look.lerpIncrement
 		if m.activeAlert.curLerpStep > 1 {
 			m.activeAlert.curLerpStep = 1
 		}
@@ -118,15 +268,39 @@ func (m AlertModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.activeAlert == nil {
 			break
 		}
-		if msg.String() != "esc" {
-			break
+		switch msg.String() {
+		case m.copyKey:
+			return m, m.CopyAlertCmd()
+		case m.detailsKey:
+			if len(m.activeAlert.details) > 0 {
+				m.activeAlert.expanded = !m.activeAlert.expanded
+			}
+		case "esc":
+			if !m.allowEscToClose {
+				break
+			}
+			m.activeAlert = nil
 		}
-		if !m.allowEscToClose {
+
+	case tea.FocusMsg:
+		m.blurred = false
+
+	case tea.BlurMsg:
+		m.blurred = true
+
+	case copiedMsg:
+		// Repeats and updates replace the alert on screen, so match it by
+		// ID, or by type and message, rather than by identity.
+		if msg.err != nil || m.activeAlert == nil || !msg.alert.repeatedBy(m.activeAlert) {
 			break
 		}
-		m.activeAlert = nil
+		m.activeAlert.flashUntil = time.Now().Add(copyFlashDuration)
 
 	default:
+		if req, ok := m.interceptAlert(msg); ok {
+			return m.raise(req)
+		}
+
 		// For any other message type, keep ticking if alert is active
 		if m.activeAlert != nil {
 			return m, tickCmd()
@@ -136,6 +310,62 @@ func (m AlertModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// raise shows the alert requested by msg. A repeat of the alert on screen is
+// merged into it instead, taking its content, extending its lifetime and
+// counting the repeats, so it doesn't fade in again or ring the bell every
+// time. Repeats that change the content, such as progress updates sharing
+// an ID, still count against the rate limit, and are only announced again
+// if they change the alert type, such as a job that failed.
+func (m AlertModel) raise(msg alertMsg) (AlertModel, tea.Cmd) {
+	if msg.dur <= 0 {
+		msg.dur = m.duration
+	}
+
+	next := m.newAlert(msg)
+	if next == nil {
+		// Requests for unregistered alert types leave the alert on screen be.
+		return m, msg.next
+	}
+
+	prev := m.activeAlert
+	repeat := prev != nil && prev.repeatedBy(next)
+	if !repeat || prev.key != next.key || prev.message != next.message {
+		if ok, cmd := m.allow(msg, next); !ok {
+			return m, tea.Batch(msg.next, cmd)
+		}
+	}
+
+	if repeat {
+		next.count = prev.count + 1
+		next.curLerpStep = prev.curLerpStep
+		next.expanded = prev.expanded && len(next.details) > 0
+		next.flashUntil = prev.flashUntil
+		m.activeAlert = next
+		if prev.key != next.key {
+			return m, tea.Batch(msg.next, m.notifyCmd(next)) // Already ticking
+		}
+		return m, msg.next // Already ticking
+	}
+
+	m.activeAlert = next
+	return m, tea.Batch(tickCmd(), msg.next, m.notifyCmd(next)) // Start ticking when new alert appears
+}
+
+// allow reports whether the rate limit lets the alert requested by msg be
+// raised, returning the command summarizing it later otherwise.
+func (m AlertModel) allow(msg alertMsg, n *alert) (bool, tea.Cmd) {
+	if m.limiter == nil || msg.summary {
+		return true, nil
+	}
+	return m.limiter.allow(n.key, n.message, time.Now())
+}
+
+// notifyCmd returns the commands announcing a newly raised alert outside of
+// the view, such as desktop notifications.
+func (m AlertModel) notifyCmd(n *alert) tea.Cmd {
+	return tea.Batch(m.announceCmd(n), m.desktopCmd(n))
+}
+
 // HasActiveAlert allows other models to tell if there is an active already and
 // avoid processing an esc key used to clear an alert
 func (m AlertModel) HasActiveAlert() bool {
@@ -225,7 +455,7 @@ func (m AlertModel) buildLineForPosition(contentLine string, notifLines []string
 		}
 
 		// Check if this specific line is shorter than the overlay position
-		contentLineWidth := ansi.PrintableRuneWidth(contentLine)
+		contentLineWidth := printableWidth(contentLine)
 		if contentLineWidth < keepWidth {
 			// Pad the line to reach the overlay position
 			padding := strings.Repeat(" ", keepWidth-contentLineWidth)
@@ -252,7 +482,7 @@ func (m AlertModel) overlayCenter(contentLine, notifLine string, notifWidth, con
 		leftPad = 0
 	}
 
-	contentLen := ansi.PrintableRuneWidth(contentLine)
+	contentLen := printableWidth(contentLine)
 
 	// If content line is shorter than where notification should start, just overlay at 0
 	if contentLen < leftPad {
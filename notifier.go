@@ -0,0 +1,187 @@
+package bubbleup
+
+import (
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Default number of alerts a Notifier holds while waiting on its program.
+const DefaultNotifierBufferSize = 64
+
+// BackpressurePolicy decides what a Notifier does with a new alert when its
+// buffer is already full.
+type BackpressurePolicy int
+
+const (
+	// DropNewest discards the incoming alert.
+	DropNewest BackpressurePolicy = iota
+
+	// DropOldest discards the oldest buffered alert to make room.
+	DropOldest
+
+	// Coalesce replaces the buffered alert with the same key, since only the
+	// latest alert is shown anyway, falling back to DropOldest.
+	Coalesce
+)
+
+// NotifierOptions configures a Notifier.
+type NotifierOptions struct {
+	// (Opt) Number of alerts buffered while the program is busy or not yet
+	// running. Defaults to DefaultNotifierBufferSize
+	BufferSize int
+
+	// (Opt) What to do when the buffer is full. Defaults to DropNewest
+	Policy BackpressurePolicy
+}
+
+// Notifier raises alerts in a BubbleTea program from outside of Update.
+// All of its methods are safe to call concurrently from any goroutine and
+// never block: alerts are buffered and delivered in order by a background
+// goroutine, including alerts raised before the program starts running.
+type Notifier struct {
+	program *tea.Program
+	opts    NotifierOptions
+
+	mu      sync.Mutex
+	queue   []alertMsg
+	wake    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+// NewNotifier returns a Notifier delivering alerts to the given program.
+// Opts may be nil to use the defaults. Call Close once it is no longer needed.
+func NewNotifier(p *tea.Program, opts *NotifierOptions) *Notifier {
+	n := &Notifier{
+		program: p,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if opts != nil {
+		n.opts = *opts
+	}
+	if n.opts.BufferSize <= 0 {
+		n.opts.BufferSize = DefaultNotifierBufferSize
+	}
+
+	go n.run()
+
+	return n
+}
+
+// Info raises an alert of the included Info type.
+func (n *Notifier) Info(msg string) { n.Send(InfoKey, msg) }
+
+// Warn raises an alert of the included Warn type.
+func (n *Notifier) Warn(msg string) { n.Send(WarnKey, msg) }
+
+// Error raises an alert of the included Error type.
+func (n *Notifier) Error(msg string) { n.Send(ErrorKey, msg) }
+
+// Debug raises an alert of the included Debug type.
+func (n *Notifier) Debug(msg string) { n.Send(DebugKey, msg) }
+
+// Send raises an alert of the given registered type.
+func (n *Notifier) Send(alertType, msg string) {
+	n.enqueue(alertMsg{alertKey: alertType, msg: msg})
+}
+
+// Dropped returns how many alerts were discarded because the buffer was full.
+func (n *Notifier) Dropped() uint64 {
+	return n.dropped.Load()
+}
+
+// Close stops delivering alerts and discards any still buffered. An alert
+// already handed to the program is delivered once the program runs or exits.
+func (n *Notifier) Close() {
+	n.once.Do(func() {
+		close(n.done)
+
+		n.mu.Lock()
+		n.queue = nil
+		n.mu.Unlock()
+	})
+}
+
+// enqueue buffers msg according to the back-pressure policy and wakes the
+// delivery goroutine.
+func (n *Notifier) enqueue(msg alertMsg) {
+	select {
+	case <-n.done:
+		return
+	default:
+	}
+
+	n.mu.Lock()
+	if len(n.queue) >= n.opts.BufferSize {
+		n.dropped.Add(1)
+		switch n.opts.Policy {
+		case DropNewest:
+			n.mu.Unlock()
+			return
+		case Coalesce:
+			if i := n.indexOfKey(msg.alertKey); i >= 0 {
+				n.queue = append(n.queue[:i], n.queue[i+1:]...)
+				break
+			}
+			n.queue = n.queue[1:]
+		default:
+			n.queue = n.queue[1:]
+		}
+	}
+	n.queue = append(n.queue, msg)
+	n.mu.Unlock()
+
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// indexOfKey returns the index of the first buffered alert with the given
+// key, or -1. Must be called with mu held.
+func (n *Notifier) indexOfKey(key string) int {
+	for i, queued := range n.queue {
+		if queued.alertKey == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// pop removes and returns the oldest buffered alert.
+func (n *Notifier) pop() (alertMsg, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.queue) == 0 {
+		return alertMsg{}, false
+	}
+	msg := n.queue[0]
+	n.queue = n.queue[1:]
+	return msg, true
+}
+
+// run delivers buffered alerts to the program until the Notifier is closed.
+// Program.Send blocks until the program is running, which is what holds
+// alerts raised early in the buffer.
+func (n *Notifier) run() {
+	for {
+		select {
+		case <-n.done:
+			return
+		case <-n.wake:
+		}
+
+		for {
+			msg, ok := n.pop()
+			if !ok {
+				break
+			}
+			n.program.Send(msg)
+		}
+	}
+}
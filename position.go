@@ -1,5 +1,10 @@
 package bubbleup
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Position string
 
 func (p Position) IsValid() bool {
@@ -52,3 +57,49 @@ const (
 	BottomRightPosition  Position = "BR"
 	UnspecifiedPosition  Position = ""
 )
+
+// MarshalText encodes the position by its String name, such as "top-left",
+// so positions can be written to config files.
+func (p Position) MarshalText() ([]byte, error) {
+	if p == UnspecifiedPosition {
+		return []byte{}, nil
+	}
+	if !p.IsValid() {
+		return nil, fmt.Errorf("unknown position %q", string(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a position from its String name, such as
+// "bottom-right". The short forms used by the constants, such as "BR", are
+// accepted too. Case is ignored.
+func (p *Position) UnmarshalText(text []byte) error {
+	name := strings.TrimSpace(string(text))
+	if name == "" {
+		*p = UnspecifiedPosition
+		return nil
+	}
+
+	for _, pos := range positions {
+		if strings.EqualFold(name, pos.String()) || strings.EqualFold(name, string(pos)) {
+			*p = pos
+			return nil
+		}
+	}
+
+	names := make([]string, len(positions))
+	for i, pos := range positions {
+		names[i] = pos.String()
+	}
+	return fmt.Errorf("unknown position %q, must be one of %s", name, strings.Join(names, ", "))
+}
+
+// positions lists every valid Position, in display order.
+var positions = []Position{
+	TopLeftPosition,
+	TopCenterPosition,
+	TopRightPosition,
+	BottomLeftPosition,
+	BottomCenterPosition,
+	BottomRightPosition,
+}
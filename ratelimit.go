@@ -0,0 +1,256 @@
+package bubbleup
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Defaults used by WithRateLimit.
+const (
+	DefaultRateLimitWindow = 2 * time.Second
+	DefaultHistorySize     = 100
+)
+
+// Most suppressed alerts listed in the details of a summary alert.
+const summaryDetailsLimit = 10
+
+// RateLimit configures WithRateLimit. Rates are token buckets: a steady
+// number of alerts per second, plus a burst that may arrive at once.
+type RateLimit struct {
+	// (Opt) Alerts per second allowed for each alert type, and how many may
+	// arrive at once. A zero rate doesn't limit alert types individually
+	PerKey      float64
+	PerKeyBurst int
+
+	// (Opt) Alerts per second allowed across all alert types, and how many
+	// may arrive at once. A zero rate doesn't limit alerts overall
+	Global      float64
+	GlobalBurst int
+
+	// (Opt) How long suppressed alerts are collected before a summary alert
+	// reports them. Defaults to DefaultRateLimitWindow
+	Window time.Duration
+
+	// (Opt) How many suppressed alerts History keeps. Defaults to
+	// DefaultHistorySize
+	HistorySize int
+}
+
+// SuppressedAlert is an alert held back by the rate limit, as listed by
+// History.
+type SuppressedAlert struct {
+	Key     string
+	Message string
+	Time    time.Time
+}
+
+// rateSummaryMsg ends an aggregation window, raising the summary alert.
+type rateSummaryMsg struct {
+	limiter *rateLimiter
+}
+
+// tokenBucket holds the tokens of one rate, refilled as time passes.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens earned since the last refill, up to burst.
+func (b *tokenBucket) refill(rate float64, burst int, now time.Time) {
+	capacity := float64(max(burst, 1))
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else {
+		b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+}
+
+// rateLimiter holds the token buckets and suppressed alerts of
+// WithRateLimit.
+type rateLimiter struct {
+	limit RateLimit
+
+	mu         sync.Mutex
+	global     tokenBucket
+	perKey     map[string]*tokenBucket
+	suppressed map[string]int // by key, since the window opened
+	windowOpen bool
+	openedAt   time.Time
+	history    []SuppressedAlert
+}
+
+// WithRateLimit returns a new AlertModel that limits how often alerts are
+// raised. Alerts over the limit are suppressed: rather than being shown,
+// they're recorded in History, and once the aggregation window closes a
+// single summary alert reports them, such as "37 warnings in the last 2s".
+// The most recent of them are listed in the summary's details, which the key
+// bound with WithDetailsKey expands. Repeats of the alert on screen are
+// merged into it as usual, without counting against the limit.
+func (m AlertModel) WithRateLimit(limit RateLimit) AlertModel {
+	if limit.Window <= 0 {
+		limit.Window = DefaultRateLimitWindow
+	}
+	if limit.HistorySize <= 0 {
+		limit.HistorySize = DefaultHistorySize
+	}
+
+	m.limiter = &rateLimiter{
+		limit:      limit,
+		perKey:     map[string]*tokenBucket{},
+		suppressed: map[string]int{},
+	}
+	return m
+}
+
+// History returns the most recent alerts suppressed by the rate limit,
+// oldest first. Returns nil if WithRateLimit wasn't used.
+func (m AlertModel) History() []SuppressedAlert {
+	if m.limiter == nil {
+		return nil
+	}
+
+	m.limiter.mu.Lock()
+	defer m.limiter.mu.Unlock()
+	return slices.Clone(m.limiter.history)
+}
+
+// allow reports whether an alert with key may be raised now. Otherwise the
+// alert is recorded as suppressed, and the returned command closes the
+// aggregation window it opened, if it's the first one suppressed.
+func (l *rateLimiter) allow(key, message string, now time.Time) (bool, tea.Cmd) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.perKey[key]
+	if !ok {
+		bucket = &tokenBucket{}
+		l.perKey[key] = bucket
+	}
+	l.global.refill(l.limit.Global, l.limit.GlobalBurst, now)
+	bucket.refill(l.limit.PerKey, l.limit.PerKeyBurst, now)
+
+	globalOK := l.limit.Global <= 0 || l.global.tokens >= 1
+	keyOK := l.limit.PerKey <= 0 || bucket.tokens >= 1
+	if globalOK && keyOK {
+		// Only take tokens once both limits agree, so an alert rejected by
+		// one limit doesn't use up the other.
+		if l.limit.Global > 0 {
+			l.global.tokens--
+		}
+		if l.limit.PerKey > 0 {
+			bucket.tokens--
+		}
+		return true, nil
+	}
+
+	l.history = append(l.history, SuppressedAlert{Key: key, Message: message, Time: now})
+	if overflow := len(l.history) - l.limit.HistorySize; overflow > 0 {
+		l.history = slices.Delete(l.history, 0, overflow)
+	}
+	l.suppressed[key]++
+
+	if l.windowOpen {
+		return false, nil
+	}
+	l.windowOpen = true
+	l.openedAt = now
+	return false, tea.Tick(l.limit.Window, func(time.Time) tea.Msg {
+		return rateSummaryMsg{limiter: l}
+	})
+}
+
+// summary closes the aggregation window, returning the summary alert for
+// the alerts suppressed during it.
+func (l *rateLimiter) summary() (alertMsg, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counts := l.suppressed
+	l.suppressed = map[string]int{}
+	l.windowOpen = false
+	if len(counts) == 0 {
+		return alertMsg{}, false
+	}
+
+	keys := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(keys, func(a, b string) int {
+		if severity(a) != severity(b) {
+			return severity(b) - severity(a)
+		}
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%d %s", counts[key], alertNoun(key, counts[key]))
+	}
+
+	msg := fmt.Sprintf("%s in the last %s", strings.Join(parts, ", "), l.limit.Window)
+	// Raised with the most severe alert type suppressed.
+	return alertMsg{alertKey: keys[0], msg: msg, details: l.windowDetails(), summary: true}, true
+}
+
+// windowDetails lists the most recent alerts suppressed since the window
+// opened, as details of the summary alert. Must be called with mu held.
+func (l *rateLimiter) windowDetails() []errorDetail {
+	start := len(l.history)
+	for start > 0 && !l.history[start-1].Time.Before(l.openedAt) {
+		start--
+	}
+	window := l.history[start:]
+
+	var details []errorDetail
+	if omitted := len(window) - summaryDetailsLimit; omitted > 0 {
+		details = append(details, errorDetail{text: fmt.Sprintf("%d earlier alerts not listed", omitted)})
+		window = window[omitted:]
+	}
+	for _, suppressed := range window {
+		prefix, _ := SpokenIcons.Icon(suppressed.Key)
+		details = append(details, errorDetail{text: prefix + " " + notificationText(suppressed.Message)})
+	}
+	return details
+}
+
+// severity ranks the included alert types, so summaries take the most
+// severe one. Other alert types rank below them.
+func severity(key string) int {
+	switch key {
+	case ErrorKey:
+		return 4
+	case WarnKey:
+		return 3
+	case InfoKey:
+		return 2
+	case DebugKey:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// alertNoun names count alerts of the alert type key, such as "warnings".
+func alertNoun(key string, count int) string {
+	var noun string
+	switch key {
+	case ErrorKey:
+		noun = "error"
+	case WarnKey:
+		noun = "warning"
+	default:
+		noun = key + " alert"
+	}
+	if count != 1 {
+		noun += "s"
+	}
+	return noun
+}
@@ -0,0 +1,167 @@
+package bubbleup
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Errors returned when registering an invalid alert type.
+var (
+	ErrEmptyAlertKey     = errors.New("alert type key is empty")
+	ErrInvalidAlertColor = errors.New("invalid alert type color")
+	ErrDuplicateAlertKey = errors.New("alert type already registered")
+)
+
+// errUnknownAlertKey aborts removing an alert type that isn't registered.
+var errUnknownAlertKey = errors.New("alert type not registered")
+
+// Registry is a set of alert types, keyed by AlertDefinition.Key.
+//
+// A Registry is safe for concurrent use. Lookups never block, and every
+// change swaps in an updated copy of the set, so readers never observe a
+// half-applied change. A Registry can be shared by many AlertModels, such as
+// one per session of a multi-user server, or cloned to give a model its own.
+// The zero value is an empty Registry ready to use.
+type Registry struct {
+	mu    sync.Mutex // serializes changes
+	types atomic.Pointer[map[string]AlertDefinition]
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Clone returns a new Registry holding the same alert types. Later changes
+// to either Registry do not affect the other.
+func (r *Registry) Clone() *Registry {
+	clone := &Registry{}
+	if r == nil {
+		return clone
+	}
+	if types := r.types.Load(); types != nil {
+		// Sets are never modified once stored, so they can be shared.
+		clone.types.Store(types)
+	}
+	return clone
+}
+
+// Register adds the provided AlertDefinition. Returns an error if the
+// definition is invalid, or if an alert type is already registered under its key.
+func (r *Registry) Register(definition AlertDefinition) error {
+	if err := validateAlertDefinition(definition); err != nil {
+		return err
+	}
+
+	return r.update(func(types map[string]AlertDefinition) error {
+		if _, ok := types[definition.Key]; ok {
+			return fmt.Errorf("%w: %q", ErrDuplicateAlertKey, definition.Key)
+		}
+		types[definition.Key] = definition
+		return nil
+	})
+}
+
+// Replace adds the provided AlertDefinition, overwriting any alert type
+// already registered under its key. Returns an error if the definition is invalid.
+func (r *Registry) Replace(definition AlertDefinition) error {
+	if err := validateAlertDefinition(definition); err != nil {
+		return err
+	}
+
+	return r.update(func(types map[string]AlertDefinition) error {
+		types[definition.Key] = definition
+		return nil
+	})
+}
+
+// Unregister removes the alert type registered under key.
+// Returns false if there was no such alert type.
+func (r *Registry) Unregister(key string) bool {
+	err := r.update(func(types map[string]AlertDefinition) error {
+		if _, ok := types[key]; !ok {
+			return errUnknownAlertKey
+		}
+		delete(types, key)
+		return nil
+	})
+	return err == nil
+}
+
+// Lookup returns the definition of the alert type registered under key.
+func (r *Registry) Lookup(key string) (AlertDefinition, bool) {
+	if r == nil {
+		return AlertDefinition{}, false
+	}
+	definition, ok := r.load()[key]
+	return definition, ok
+}
+
+// Definitions returns the definitions of all registered alert types, sorted by key.
+func (r *Registry) Definitions() []AlertDefinition {
+	if r == nil {
+		return nil
+	}
+	definitions := slices.Collect(maps.Values(r.load()))
+	slices.SortFunc(definitions, func(a, b AlertDefinition) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return definitions
+}
+
+// load returns the current set of alert types, which must not be modified.
+func (r *Registry) load() map[string]AlertDefinition {
+	if types := r.types.Load(); types != nil {
+		return *types
+	}
+	return nil
+}
+
+// update applies fn to a copy of the current set and stores the copy,
+// unless fn returns an error.
+func (r *Registry) update(fn func(types map[string]AlertDefinition) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := maps.Clone(r.load())
+	if next == nil {
+		next = make(map[string]AlertDefinition)
+	}
+	if err := fn(next); err != nil {
+		return err
+	}
+
+	r.types.Store(&next)
+	return nil
+}
+
+// validateAlertDefinition checks that definition can be rendered.
+func validateAlertDefinition(definition AlertDefinition) error {
+	if definition.Key == "" {
+		return ErrEmptyAlertKey
+	}
+
+	if definition.Color != nil {
+		if err := validateColor(definition.Color); err != nil {
+			return fmt.Errorf("%w for alert type %q: %v", ErrInvalidAlertColor, definition.Key, err)
+		}
+	} else if _, err := colorful.Hex(definition.ForeColor); err != nil {
+		return fmt.Errorf("%w %q for alert type %q: must be a hex code like \"#00FF00\"",
+			ErrInvalidAlertColor, definition.ForeColor, definition.Key)
+	}
+
+	if definition.BackColor != nil {
+		if err := validateColor(definition.BackColor); err != nil {
+			return fmt.Errorf("%w for background of alert type %q: %v", ErrInvalidAlertColor, definition.Key, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package bubbleup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SlogHandlerOptions configures a SlogHandler.
+type SlogHandlerOptions struct {
+	// (Opt) Minimum level of records raised as alerts. Defaults to slog.LevelInfo
+	Level slog.Leveler
+
+	// (Opt) Handler that also receives every record, such as one writing to a file
+	Next slog.Handler
+
+	// (Opt) Maps a record level to a registered alert key. Defaults to LevelAlertKey
+	AlertKey func(slog.Level) string
+
+	// (Opt) How long alerts are shown. Defaults to the AlertModel's duration
+	Duration time.Duration
+}
+
+// SlogHandler is a slog.Handler that raises an alert in a running BubbleTea
+// program for every record at or above its minimum level. The alert body is
+// the record message followed by its attributes in key=value form.
+//
+// Handle never blocks on the program, so it is safe to log from any goroutine,
+// including from within Update.
+type SlogHandler struct {
+	send  func(alertMsg)
+	opts  SlogHandlerOptions
+	attrs []string
+	group string
+}
+
+// SlogHandler returns a SlogHandler raising alerts through the Notifier,
+// which delivers records in order and sets the buffer size and policy for
+// when the program is busy or not yet running. Opts may be nil to use the
+// defaults. Records are dropped once the Notifier is closed.
+func (n *Notifier) SlogHandler(opts *SlogHandlerOptions) *SlogHandler {
+	return newSlogHandler(n.enqueue, opts)
+}
+
+func newSlogHandler(send func(alertMsg), opts *SlogHandlerOptions) *SlogHandler {
+	h := &SlogHandler{send: send}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	if h.opts.AlertKey == nil {
+		h.opts.AlertKey = LevelAlertKey
+	}
+	return h
+}
+
+// LevelAlertKey maps a slog level to the key of the included alert type with
+// the matching severity.
+func LevelAlertKey(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugKey
+	case level < slog.LevelWarn:
+		return InfoKey
+	case level < slog.LevelError:
+		return WarnKey
+	default:
+		return ErrorKey
+	}
+}
+
+// Enabled reports whether the record would either raise an alert or be
+// passed on to the next handler.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.opts.Level.Level() {
+		return true
+	}
+	return h.opts.Next != nil && h.opts.Next.Enabled(ctx, level)
+}
+
+// Handle raises an alert for the record if it meets the minimum level, and
+// passes it on to the next handler if there is one.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.opts.Level.Level() {
+		h.send(alertMsg{
+			alertKey: h.opts.AlertKey(r.Level),
+			msg:      h.format(r),
+			dur:      h.opts.Duration,
+		})
+	}
+
+	if h.opts.Next != nil && h.opts.Next.Enabled(ctx, r.Level) {
+		return h.opts.Next.Handle(ctx, r)
+	}
+	return nil
+}
+
+// WithAttrs returns a handler that includes attrs in every alert.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := *h
+	clone.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], formatAttrs(h.group, attrs)...)
+	if h.opts.Next != nil {
+		clone.opts.Next = h.opts.Next.WithAttrs(attrs)
+	}
+	return &clone
+}
+
+// WithGroup returns a handler that qualifies later attributes with name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.group = h.group + name + "."
+	if h.opts.Next != nil {
+		clone.opts.Next = h.opts.Next.WithGroup(name)
+	}
+	return &clone
+}
+
+// format builds the alert body from the record's message and attributes.
+func (h *SlogHandler) format(r slog.Record) string {
+	parts := make([]string, 0, 1+len(h.attrs)+r.NumAttrs())
+	parts = append(parts, r.Message)
+	parts = append(parts, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		parts = append(parts, formatAttrs(h.group, []slog.Attr{a})...)
+		return true
+	})
+	return strings.Join(parts, " ")
+}
+
+// formatAttrs renders attrs as key=value pairs, flattening groups into
+// dotted keys.
+func formatAttrs(prefix string, attrs []slog.Attr) []string {
+	var out []string
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+
+		if a.Value.Kind() == slog.KindGroup {
+			group := prefix
+			if a.Key != "" {
+				group += a.Key + "."
+			}
+			out = append(out, formatAttrs(group, a.Value.Group())...)
+			continue
+		}
+
+		out = append(out, fmt.Sprintf("%s%s=%s", prefix, a.Key, formatValue(a.Value)))
+	}
+	return out
+}
+
+// formatValue quotes values that would otherwise be ambiguous in key=value form.
+func formatValue(v slog.Value) string {
+	s := v.String()
+	if s == "" || strings.ContainsAny(s, " =\"\n\t") {
+		return strconv.Quote(s)
+	}
+	return s
+}
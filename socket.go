@@ -0,0 +1,285 @@
+package bubbleup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Limits of the alert socket.
+const (
+	// Requests buffered by ListenAlerts before connections wait for the
+	// program to catch up
+	socketBufferSize = 16
+
+	// Longest request line accepted, in bytes
+	socketMaxRequestSize = 64 * 1024
+)
+
+// ErrSocketInUse is returned by ListenAlerts when another process is
+// already listening on the socket.
+var ErrSocketInUse = errors.New("alert socket already in use")
+
+// socketRequest is the wire format of an AlertRequest: one JSON object per
+// line, such as
+//
+//	{"key": "Warn", "message": "Disk almost full", "duration": "5s", "position": "top-right", "id": "disk"}
+type socketRequest struct {
+	Key      string   `json:"key"`
+	Message  string   `json:"message"`
+	Duration Duration `json:"duration,omitempty"`
+	Position Position `json:"position,omitempty"`
+	ID       string   `json:"id,omitempty"`
+	Link     string   `json:"link,omitempty"`
+}
+
+// socketReply answers each request line, with Error set if it was rejected.
+type socketReply struct {
+	Error string `json:"error,omitempty"`
+}
+
+// ListenAlerts listens on the Unix domain socket at path, letting other
+// processes, such as helper scripts, raise alerts with SendAlert. Requests
+// are delivered on the returned channel, ready for SubscribeAlerts:
+//
+//	requests, err := bubbleup.ListenAlerts(ctx, "/run/user/1000/myapp.sock")
+//	...
+//	return bubbleup.SubscribeAlerts(ctx, requests) // from Init()
+//
+// The socket is only ever accessible to the current user, which is what
+// authenticates clients. It is created in a private directory next to path
+// and moved into place once its permissions are set, so that directory must
+// be writable. A stale socket left behind by a crashed process is replaced,
+// while one still in use returns ErrSocketInUse. Once ctx is cancelled the
+// socket is removed and the channel closed.
+//
+// Each request is a line of JSON with "key" and "message", and optionally
+// "duration" (such as "5s"), "position" (such as "top-right"), "id" and
+// "link". Every line is answered with a line of JSON, holding an "error" if
+// the request was rejected. Requests for alert types the model doesn't have
+// registered are accepted, and then ignored by it; use the AlertModel's
+// ListenAlerts method to reject them instead.
+func ListenAlerts(ctx context.Context, path string) (<-chan AlertRequest, error) {
+	return listenAlerts(ctx, path, nil)
+}
+
+// ListenAlerts works like the ListenAlerts function, but also rejects
+// requests for alert types that aren't registered with the model's Registry,
+// so SendAlert reports them instead of the alert silently not appearing.
+func (m AlertModel) ListenAlerts(ctx context.Context, path string) (<-chan AlertRequest, error) {
+	return listenAlerts(ctx, path, m.registry)
+}
+
+// listenAlerts listens on the socket, rejecting requests for alert types
+// missing from registry unless it is nil.
+func listenAlerts(ctx context.Context, path string, registry *Registry) (<-chan AlertRequest, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	ln, err := listenPrivate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make(chan AlertRequest, socketBufferSize)
+	var conns sync.WaitGroup
+
+	go func() {
+		<-ctx.Done()
+		// Remove the socket before the channel closes, which closing the
+		// listener leads to.
+		os.Remove(path)
+		ln.Close()
+	}()
+
+	go func() {
+		defer close(requests)
+		defer conns.Wait()
+
+		var delay time.Duration
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err != nil {
+				// Errors such as running out of file descriptors are usually
+				// temporary, so retry with a growing delay, like net/http does.
+				delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+				continue
+			}
+			delay = 0
+
+			conns.Add(1)
+			go func() {
+				defer conns.Done()
+				serveAlertConn(ctx, conn, requests, registry)
+			}()
+		}
+	}()
+
+	return requests, nil
+}
+
+// listenPrivate listens on a Unix socket at path that no other user can
+// access at any point: it is created in a new directory only the current
+// user can enter, made private there, and then moved to path. The listener
+// doesn't remove the moved socket when closed.
+func listenPrivate(path string) (*net.UnixListener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".bubbleup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	ln.SetUnlinkOnClose(false)
+
+	if err = os.Chmod(tmp, 0o600); err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes the socket at path if nothing listens on it.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%w: %s", ErrSocketInUse, path)
+	}
+	return os.Remove(path)
+}
+
+// serveAlertConn reads request lines from conn until it's closed or ctx is
+// cancelled.
+func serveAlertConn(ctx context.Context, conn net.Conn, requests chan<- AlertRequest, registry *Registry) {
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, socketMaxRequestSize)
+	replies := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var reply socketReply
+		req, err := parseSocketRequest(scanner.Bytes(), registry)
+		if err != nil {
+			reply.Error = err.Error()
+		} else {
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := replies.Encode(reply); err != nil {
+			return
+		}
+	}
+}
+
+func parseSocketRequest(line []byte, registry *Registry) (AlertRequest, error) {
+	var wire socketRequest
+	if err := json.Unmarshal(line, &wire); err != nil {
+		return AlertRequest{}, err
+	}
+	if wire.Key == "" {
+		return AlertRequest{}, ErrEmptyAlertKey
+	}
+	if registry != nil {
+		if _, ok := registry.Lookup(wire.Key); !ok {
+			return AlertRequest{}, fmt.Errorf("%w: %q", errUnknownAlertKey, wire.Key)
+		}
+	}
+	if wire.Message == "" {
+		return AlertRequest{}, errors.New("alert message is empty")
+	}
+
+	return AlertRequest{
+		Key:      wire.Key,
+		Message:  wire.Message,
+		Duration: time.Duration(wire.Duration),
+		Link:     wire.Link,
+		Position: wire.Position,
+		ID:       wire.ID,
+	}, nil
+}
+
+// SendAlert raises an alert in the program listening on the socket at path
+// with ListenAlerts. Returns an error if the program can't be reached or
+// rejects the request.
+func SendAlert(ctx context.Context, path string, req AlertRequest) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	err = json.NewEncoder(conn).Encode(socketRequest{
+		Key:      req.Key,
+		Message:  req.Message,
+		Duration: Duration(req.Duration),
+		Position: req.Position,
+		ID:       req.ID,
+		Link:     req.Link,
+	})
+	if err != nil {
+		return err
+	}
+
+	var reply socketReply
+	if err := json.NewDecoder(conn).Decode(&reply); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("reading reply: %w", err)
+	}
+	if reply.Error != "" {
+		return fmt.Errorf("alert rejected: %s", reply.Error)
+	}
+	return nil
+}
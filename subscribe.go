@@ -0,0 +1,68 @@
+package bubbleup
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AlertRequest describes an alert raised from outside of Update, such as one
+// streamed over a channel to SubscribeAlerts.
+type AlertRequest struct {
+	// (Req) Key of a registered alert type
+	Key string
+
+	// (Req) Message to display
+	Message string
+
+	// (Opt) How long the alert is shown. Defaults to the AlertModel's duration
+	Duration time.Duration
+
+	// (Opt) URL or file path the alert links to, see NewLinkAlertCmd
+	Link string
+
+	// (Opt) Where the alert is shown. Defaults to the AlertModel's position
+	Position Position
+
+	// (Opt) Identifies the alert, such as the name of a job reporting its
+	// progress. A request with the ID of the alert on screen is merged into
+	// it as a repeat, updating it in place rather than fading in a new alert.
+	// Without an ID, only alerts with the same key and message are merged
+	ID string
+}
+
+func (r AlertRequest) alertMsg() alertMsg {
+	return alertMsg{
+		alertKey: r.Key,
+		msg:      r.Message,
+		dur:      r.Duration,
+		link:     r.Link,
+		position: r.Position,
+		id:       r.ID,
+	}
+}
+
+// SubscribeAlerts returns a tea.Cmd that waits for the next AlertRequest on
+// ch and raises it as an alert. The AlertModel re-arms the command after each
+// alert, so a single call keeps the stream flowing for as long as it lasts.
+// Listening stops once ch is closed or ctx is cancelled.
+//
+// Return the command from your Init(), or batch it into any Update() return.
+func SubscribeAlerts(ctx context.Context, ch <-chan AlertRequest) tea.Cmd {
+	var listen tea.Cmd
+	listen = func() tea.Msg {
+		select {
+		case <-ctx.Done():
+			return nil
+		case req, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			msg := req.alertMsg()
+			msg.next = listen
+			return msg
+		}
+	}
+	return listen
+}
@@ -0,0 +1,238 @@
+package bubbleup
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme bundles everything about how alerts look, so it can be applied to an
+// AlertModel in one go with WithTheme. Start from one of the presets, such
+// as DefaultTheme or CatppuccinTheme, and adjust what you need.
+type Theme struct {
+	// Name of the theme, as accepted by ThemeByName for presets
+	Name string
+
+	// Colors of alert types, by key, taking precedence over the color they
+	// were registered with. Alert types without an entry keep their color
+	Colors map[string]lipgloss.TerminalColor
+
+	// Color alerts fade in from. Nil picks black or white to match the terminal
+	Background lipgloss.TerminalColor
+
+	// Border drawn around alerts. The zero value uses lipgloss.RoundedBorder
+	Border lipgloss.Border
+
+	// Blank columns between the border and the text, on each side
+	PaddingX int
+
+	// Icons prefixing alert messages. Nil keeps the model's current icons
+	Icons IconSet
+
+	// Whether to show the alert type's key as a title above the message,
+	// and the style applied to it on top of the alert's color
+	ShowTitle  bool
+	TitleStyle lipgloss.Style
+
+	// Whether alerts appear at full color straight away instead of fading in
+	DisableFade bool
+
+	// Fraction of the fade covered by each tick, and where the fade starts.
+	// Values <= 0 use DefaultLerpIncrement and DefaultLerpStart
+	LerpIncrement float64
+	LerpStart     float64
+}
+
+// appearance holds the presentation settings a Theme applies to alerts.
+type appearance struct {
+	colors        map[string]lipgloss.TerminalColor
+	border        lipgloss.Border
+	paddingX      int
+	showTitle     bool
+	titleStyle    lipgloss.Style
+	lerpIncrement float64
+	lerpStart     float64
+}
+
+func (t Theme) appearance() appearance {
+	look := appearance{
+		border:        t.Border,
+		paddingX:      max(t.PaddingX, 0),
+		showTitle:     t.ShowTitle,
+		titleStyle:    t.TitleStyle,
+		lerpIncrement: t.LerpIncrement,
+		lerpStart:     t.LerpStart,
+	}
+	if look.border == (lipgloss.Border{}) {
+		look.border = lipgloss.RoundedBorder()
+	}
+	if look.lerpIncrement <= 0 {
+		look.lerpIncrement = DefaultLerpIncrement
+	}
+	if look.lerpStart <= 0 {
+		look.lerpStart = DefaultLerpStart
+	}
+	if t.DisableFade {
+		look.lerpStart = 1
+	}
+	return look
+}
+
+// WithTheme returns a new AlertModel styled by the given Theme. The theme's
+// colors are applied on top of the model's Registry as alerts are raised,
+// leaving the Registry itself untouched, so alert types registered later,
+// or by other models sharing it, are picked up as usual.
+func (m AlertModel) WithTheme(t Theme) AlertModel {
+	m.look = t.appearance()
+	m.background = t.Background
+
+	if t.Icons != nil {
+		m = m.WithIconSet(t.Icons)
+	}
+
+	m.look.colors = validColors(t.Colors)
+	return m
+}
+
+// validColors returns the valid colors of a theme, so invalid ones leave
+// the alert type's color as it was. Returns nil if there are none.
+func validColors(colors map[string]lipgloss.TerminalColor) map[string]lipgloss.TerminalColor {
+	var valid map[string]lipgloss.TerminalColor
+	for key, c := range colors {
+		if validateColor(c) != nil {
+			continue
+		}
+		if valid == nil {
+			valid = map[string]lipgloss.TerminalColor{}
+		}
+		valid[key] = c
+	}
+	return valid
+}
+
+// themed returns the definition with the theme's color for its alert type,
+// if the theme has one.
+func (a appearance) themed(definition AlertDefinition) AlertDefinition {
+	if c, ok := a.colors[definition.Key]; ok {
+		definition.Color = c
+	}
+	return definition
+}
+
+// ThemeByName returns the preset theme with the given name.
+func ThemeByName(name string) (Theme, bool) {
+	for _, preset := range []func() Theme{
+		DefaultTheme,
+		MinimalTheme,
+		NvimNotifyTheme,
+		CatppuccinTheme,
+		SolarizedTheme,
+		HighContrastTheme,
+	} {
+		if t := preset(); t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// DefaultTheme is the look BubbleUp ships with.
+func DefaultTheme() Theme {
+	return Theme{
+		Name: "default",
+		Colors: map[string]lipgloss.TerminalColor{
+			InfoKey:  lipgloss.Color(InfoColor),
+			WarnKey:  lipgloss.Color(WarnColor),
+			ErrorKey: lipgloss.Color(ErrorColor),
+			DebugKey: lipgloss.Color(DebugColor),
+		},
+		Border:   lipgloss.RoundedBorder(),
+		PaddingX: 1,
+	}
+}
+
+// MinimalTheme drops the frame and the fade, and uses the terminal's own
+// palette for colors.
+func MinimalTheme() Theme {
+	return Theme{
+		Name: "minimal",
+		Colors: map[string]lipgloss.TerminalColor{
+			InfoKey:  lipgloss.ANSIColor(2),
+			WarnKey:  lipgloss.ANSIColor(3),
+			ErrorKey: lipgloss.ANSIColor(1),
+			DebugKey: lipgloss.ANSIColor(5),
+		},
+		Border:      lipgloss.HiddenBorder(),
+		PaddingX:    1,
+		DisableFade: true,
+	}
+}
+
+// NvimNotifyTheme mimics the default look of nvim-notify, with the alert
+// type shown as a title.
+func NvimNotifyTheme() Theme {
+	return Theme{
+		Name: "nvim-notify",
+		Colors: map[string]lipgloss.TerminalColor{
+			InfoKey:  lipgloss.Color("#A9FF68"),
+			WarnKey:  lipgloss.Color("#F79000"),
+			ErrorKey: lipgloss.Color("#F70067"),
+			DebugKey: lipgloss.Color("#8B8B8B"),
+		},
+		Border:     lipgloss.RoundedBorder(),
+		PaddingX:   1,
+		ShowTitle:  true,
+		TitleStyle: lipgloss.NewStyle().Bold(true),
+	}
+}
+
+// CatppuccinTheme uses the Catppuccin Latte palette on light terminals and
+// Mocha on dark ones.
+func CatppuccinTheme() Theme {
+	return Theme{
+		Name: "catppuccin",
+		Colors: map[string]lipgloss.TerminalColor{
+			InfoKey:  lipgloss.AdaptiveColor{Light: "#40A02B", Dark: "#A6E3A1"},
+			WarnKey:  lipgloss.AdaptiveColor{Light: "#DF8E1D", Dark: "#F9E2AF"},
+			ErrorKey: lipgloss.AdaptiveColor{Light: "#D20F39", Dark: "#F38BA8"},
+			DebugKey: lipgloss.AdaptiveColor{Light: "#8839EF", Dark: "#CBA6F7"},
+		},
+		Background: lipgloss.AdaptiveColor{Light: "#EFF1F5", Dark: "#1E1E2E"},
+		Border:     lipgloss.RoundedBorder(),
+		PaddingX:   1,
+	}
+}
+
+// SolarizedTheme uses the Solarized accent colors, fading in from the
+// light or dark Solarized background.
+func SolarizedTheme() Theme {
+	return Theme{
+		Name: "solarized",
+		Colors: map[string]lipgloss.TerminalColor{
+			InfoKey:  lipgloss.Color("#859900"),
+			WarnKey:  lipgloss.Color("#B58900"),
+			ErrorKey: lipgloss.Color("#DC322F"),
+			DebugKey: lipgloss.Color("#D33682"),
+		},
+		Background: lipgloss.AdaptiveColor{Light: "#FDF6E3", Dark: "#002B36"},
+		Border:     lipgloss.RoundedBorder(),
+		PaddingX:   1,
+	}
+}
+
+// HighContrastTheme uses a heavy frame, bold titles and colors with maximum
+// contrast against the terminal background, without any fade.
+func HighContrastTheme() Theme {
+	return Theme{
+		Name: "high-contrast",
+		Colors: map[string]lipgloss.TerminalColor{
+			InfoKey:  lipgloss.AdaptiveColor{Light: "#005F00", Dark: "#00FF00"},
+			WarnKey:  lipgloss.AdaptiveColor{Light: "#5F5F00", Dark: "#FFFF00"},
+			ErrorKey: lipgloss.AdaptiveColor{Light: "#AF0000", Dark: "#FF5F5F"},
+			DebugKey: lipgloss.AdaptiveColor{Light: "#5F005F", Dark: "#FF87FF"},
+		},
+		Border:      lipgloss.ThickBorder(),
+		PaddingX:    1,
+		ShowTitle:   true,
+		TitleStyle:  lipgloss.NewStyle().Bold(true).Underline(true),
+		DisableFade: true,
+	}
+}
@@ -1,13 +1,14 @@
 package bubbleup
 
 import (
-	"bytes"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/mattn/go-runewidth"
-	"github.com/muesli/reflow/ansi"
 	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
 )
 
 // Obtained from https://github.com/charmbracelet/lipgloss/blob/master/get.go
@@ -15,7 +16,7 @@ func getLines(s string) (lines []string, widest int) {
 	lines = strings.Split(s, "\n")
 
 	for _, l := range lines {
-		w := ansi.PrintableRuneWidth(l)
+		w := printableWidth(l)
 		if widest < w {
 			widest = w
 		}
@@ -24,41 +25,116 @@ func getLines(s string) (lines []string, widest int) {
 	return lines, widest
 }
 
+// escapeLen returns the length in bytes of the escape sequence starting at
+// s[i], or 0 if s[i] does not start one. CSI sequences end at their final
+// byte, while string sequences such as OSC 8 hyperlinks run until BEL or ST.
+func escapeLen(s string, i int) int {
+	if s[i] != ansi.ESC {
+		return 0
+	}
+	if i+1 >= len(s) {
+		return 1
+	}
+
+	switch s[i+1] {
+	case '[':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j - i + 1
+			}
+		}
+	case ']', 'P', 'X', '^', '_':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == ansi.BEL {
+				return j - i + 1
+			}
+			if s[j] == ansi.ESC && j+1 < len(s) && s[j+1] == '\\' {
+				return j - i + 2
+			}
+		}
+	default:
+		return 2
+	}
+
+	// Unterminated sequence; treat the rest of the string as part of it.
+	return len(s) - i
+}
+
+// hyperlinkTarget reports whether seq is an OSC 8 hyperlink sequence, and
+// if so, the URI it opens. An empty URI closes the current hyperlink.
+func hyperlinkTarget(seq string) (uri string, ok bool) {
+	rest, ok := strings.CutPrefix(seq, "\x1b]8;")
+	if !ok {
+		return "", false
+	}
+	rest = strings.TrimSuffix(strings.TrimSuffix(rest, "\x1b\\"), "\a")
+	_, uri, _ = strings.Cut(rest, ";")
+	return uri, true
+}
+
+// printableWidth returns the number of cells s occupies once escape
+// sequences are stripped.
+func printableWidth(s string) int {
+	var width int
+	for i := 0; i < len(s); {
+		if n := escapeLen(s, i); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runewidth.RuneWidth(r)
+		i += size
+	}
+	return width
+}
+
 // Obtained from https://github.com/charmbracelet/lipgloss/pull/102/commits/a075bfc9317152e674d661a2cdfe58144306e77a
 // cutLeft cuts printable characters from the left.
+// SGR sequences and any open OSC 8 hyperlink in the cut part are replayed in
+// front of the kept part so its styling and links are preserved.
 func cutLeft(s string, cutWidth int) string {
 	var (
-		pos    int
-		isAnsi bool
-		ab     bytes.Buffer
-		b      bytes.Buffer
+		pos  int
+		link string
+		ab   strings.Builder
+		b    strings.Builder
 	)
-	for _, c := range s {
-		var w int
-		if c == ansi.Marker || isAnsi {
-			isAnsi = true
-			ab.WriteRune(c)
-			if ansi.IsTerminator(c) {
-				isAnsi = false
-				if bytes.HasSuffix(ab.Bytes(), []byte("[0m")) {
-					ab.Reset()
+	for i := 0; i < len(s); {
+		if n := escapeLen(s, i); n > 0 {
+			seq := s[i : i+n]
+			i += n
+			if b.Len() > 0 {
+				b.WriteString(seq)
+				continue
+			}
+			if uri, ok := hyperlinkTarget(seq); ok {
+				link = ""
+				if uri != "" {
+					link = seq
 				}
+				continue
+			}
+			ab.WriteString(seq)
+			if strings.HasSuffix(seq, "[0m") {
+				ab.Reset()
 			}
-		} else {
-			w = runewidth.RuneWidth(c)
+			continue
 		}
 
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		w := runewidth.RuneWidth(r)
+
 		if pos >= cutWidth {
 			if b.Len() == 0 {
-				if ab.Len() > 0 {
-					b.Write(ab.Bytes())
-				}
+				b.WriteString(ab.String())
+				b.WriteString(link)
 				if pos-cutWidth > 1 {
 					b.WriteByte(' ')
 					continue
 				}
 			}
-			b.WriteRune(c)
+			b.WriteRune(r)
 		}
 		pos += w
 	}
@@ -67,40 +143,44 @@ func cutLeft(s string, cutWidth int) string {
 
 // cutRight keeps printable characters from the left, up to keepWidth cells.
 // ANSI escape sequences are preserved. Complement to cutLeft().
+// A hyperlink left open at the cut is closed so it doesn't spill onto
+// whatever is drawn after the kept part.
 func cutRight(s string, keepWidth int) string {
 	var (
-		pos    int
-		isAnsi bool
-		ab     bytes.Buffer
-		b      bytes.Buffer
+		pos  int
+		link bool
+		b    strings.Builder
 	)
 
-	for _, c := range s {
-		var w int
-		if c == ansi.Marker || isAnsi {
-			isAnsi = true
-			ab.WriteRune(c)
-			if ansi.IsTerminator(c) {
-				isAnsi = false
-				b.Write(ab.Bytes())
-				ab.Reset()
+	for i := 0; i < len(s); {
+		if n := escapeLen(s, i); n > 0 {
+			seq := s[i : i+n]
+			i += n
+			if uri, ok := hyperlinkTarget(seq); ok {
+				link = uri != ""
 			}
+			b.WriteString(seq)
 			continue
 		}
 
-		w = runewidth.RuneWidth(c)
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := runewidth.RuneWidth(r)
 		if pos+w > keepWidth {
 			break
 		}
 
-		b.WriteRune(c)
+		b.WriteRune(r)
 		pos += w
+		i += size
+	}
+
+	if link {
+		b.WriteString(ansi.ResetHyperlink())
 	}
 
 	// Reset to avoid color bleed
-	if b.Len() > 0 && !bytes.HasSuffix(b.Bytes(), []byte("[0m")) {
-		b.WriteByte(ansi.Marker)
-		b.WriteString("[0m")
+	if b.Len() > 0 && !strings.HasSuffix(b.String(), "[0m") {
+		b.WriteString("\x1b[0m")
 	}
 
 	return b.String()
@@ -116,9 +196,15 @@ func hangingWrap(prefix, msg string, textWidth int) string {
 		return prefix + msg
 	}
 
-	// Wrap message to the available width.
-	// wordwrap.WrapString wraps on spaces; it will still break long tokens if needed.
-	wrapped := wordwrap.String(msg, avail)
+	// Wrap message to the available width on spaces, then hard wrap tokens
+	// too long for a line of their own. Breaking after hyphens is disabled
+	// since wordwrap can overshoot the width by a cell doing so, which
+	// lipgloss would then rewrap without the indent.
+	ww := wordwrap.NewWriter(avail)
+	ww.Breakpoints = nil
+	_, _ = ww.Write([]byte(msg))
+	_ = ww.Close()
+	wrapped := wrap.String(ww.String(), avail)
 
 	// Add hanging indent to subsequent lines.
 	indent := strings.Repeat(" ", indentW)
@@ -0,0 +1,117 @@
+package bubbleup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultConfigPollInterval is how often WatchConfig checks the config file
+// when no interval is given.
+const DefaultConfigPollInterval = time.Second
+
+// configReloadMsg delivers a config file that changed on disk to Update.
+type configReloadMsg struct {
+	path string
+	cfg  Config
+	err  error
+	next tea.Cmd
+}
+
+// configStamp identifies a version of the config file without reading it.
+type configStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// WatchConfig returns a tea.Cmd that polls the config file at path,
+// checking every interval whether it changed. Changes are parsed with the
+// ConfigParser for path and applied to the AlertModel in Update, as if it had been
+// created by NewAlertModelFromConfig, keeping the active alert and key
+// bindings. An Info alert confirms each reload, while an invalid file raises
+// an Error alert listing the problems and leaves the settings untouched.
+//
+// Alert types are only ever added or replaced, so removing one from the file
+// keeps it registered until restart. The AlertModel re-arms the command
+// after each change, and watching stops once ctx is cancelled. Return the
+// command from your Init(), or batch it into any Update() return.
+func WatchConfig(ctx context.Context, path string, interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		interval = DefaultConfigPollInterval
+	}
+	return watchConfig(ctx, path, interval, nil)
+}
+
+// watchConfig polls path until it differs from last. A nil last means the
+// watch is just starting, and the file as it is now becomes the baseline.
+func watchConfig(ctx context.Context, path string, interval time.Duration, last *configStamp) tea.Cmd {
+	return func() tea.Msg {
+		if last == nil {
+			stamp, _ := statConfig(path)
+			last = &stamp
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// Only read the file once a change has settled for a whole interval,
+		// so a file caught halfway through being written isn't reported.
+		var pending configStamp
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			stamp, err := statConfig(path)
+			if err != nil || stamp == *last {
+				// A missing file is usually an editor replacing it, so wait
+				// for it to reappear rather than reporting it.
+				continue
+			}
+			if stamp != pending {
+				pending = stamp
+				continue
+			}
+
+			msg := configReloadMsg{path: path, next: watchConfig(ctx, path, interval, &stamp)}
+			data, err := os.ReadFile(path)
+			if err == nil {
+				msg.cfg, err = ConfigParser(path)(data)
+			}
+			msg.err = err
+			return msg
+		}
+	}
+}
+
+func statConfig(path string) (configStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return configStamp{}, err
+	}
+	return configStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// reloadConfig applies a config delivered by WatchConfig, raising an alert
+// with the outcome.
+func (m AlertModel) reloadConfig(msg configReloadMsg) (tea.Model, tea.Cmd) {
+	name := filepath.Base(msg.path)
+
+	if msg.err == nil {
+		updated, err := m.withConfig(msg.cfg)
+		if err == nil {
+			return updated.Update(alertMsg{alertKey: InfoKey, msg: "Reloaded " + name, next: msg.next})
+		}
+		msg.err = err
+	}
+
+	alert := m.errorAlertMsg(fmt.Errorf("invalid config %s: %w", name, msg.err))
+	alert.next = msg.next
+	return m.Update(alert)
+}
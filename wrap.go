@@ -0,0 +1,73 @@
+package bubbleup
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AlertAware can be implemented by a model passed to Wrap. Before every
+// Update, the model is handed the current AlertModel so it can create alerts
+// with NewAlertCmd, or check HasActiveAlert before handling Esc itself.
+type AlertAware interface {
+	// WithAlerts returns a copy of the model holding the given AlertModel
+	WithAlerts(alerts AlertModel) tea.Model
+}
+
+// WrappedModel is the tea.Model returned by Wrap. It routes every message to
+// both the inner model and the AlertModel, batches their commands, and
+// overlays active alerts onto the inner model's view.
+type WrappedModel struct {
+	inner  tea.Model
+	alerts AlertModel
+}
+
+// Wrap returns a tea.Model that takes care of the AlertModel plumbing for
+// inner, so it no longer needs to call the AlertModel's Update and Render
+// itself. Pass the result to tea.NewProgram, and type assert the model
+// returned by Run to WrappedModel to get at the inner model.
+func Wrap(inner tea.Model, alerts AlertModel) tea.Model {
+	return WrappedModel{inner: inner, alerts: alerts}
+}
+
+// Inner returns the wrapped model.
+func (w WrappedModel) Inner() tea.Model {
+	return w.inner
+}
+
+// Alerts returns the AlertModel managing the wrapped model's alerts.
+func (w WrappedModel) Alerts() AlertModel {
+	return w.alerts
+}
+
+// Init initializes both the inner model and the AlertModel.
+// Implemented as part of BubbleTea Model interface
+func (w WrappedModel) Init() tea.Cmd {
+	w.inner = w.shareAlerts()
+	return tea.Batch(w.inner.Init(), w.alerts.Init())
+}
+
+// Update passes msg to the inner model first, so it still sees any active
+// alert, and then to the AlertModel.
+// Implemented as part of BubbleTea Model interface
+func (w WrappedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	inner, innerCmd := w.shareAlerts().Update(msg)
+	outAlert, alertCmd := w.alerts.Update(msg)
+
+	w.inner = inner
+	w.alerts = outAlert.(AlertModel)
+
+	return w, tea.Batch(innerCmd, alertCmd)
+}
+
+// View renders the inner model's view with any active alert overlaid.
+// Implemented as part of BubbleTea Model interface
+func (w WrappedModel) View() string {
+	return w.alerts.Render(w.inner.View())
+}
+
+// shareAlerts hands the current AlertModel to the inner model if it is AlertAware.
+func (w WrappedModel) shareAlerts() tea.Model {
+	if aware, ok := w.inner.(AlertAware); ok {
+		return aware.WithAlerts(w.alerts)
+	}
+	return w.inner
+}